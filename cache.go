@@ -0,0 +1,104 @@
+package main
+
+import "time"
+
+// the times after which the cached data is read again from the daemon
+const (
+	mountsTTL   = 30 * time.Second
+	networksTTL = 10 * time.Second
+	pidsTTL     = 10 * time.Second
+)
+
+// ttlCache caches values by id. The values are read again when they are
+// older than the ttl, a ttl of 0 keeps them until they are dropped. The
+// entries are guarded by the global lock.
+type ttlCache struct {
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value interface{}
+	time  time.Time
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// get returns the cached value of id or reads it with read when it is
+// missing or outdated. The daemon is queried without holding the lock, so
+// the caller must not hold it.
+func (c *ttlCache) get(id string, read func() (interface{}, error)) (interface{}, error) {
+	lock.Lock()
+	v, ok := c.lookup(id)
+	lock.Unlock()
+	if ok {
+		return v, nil
+	}
+	v, err := read()
+	if err != nil {
+		return nil, err
+	}
+	lock.Lock()
+	c.put(id, v)
+	lock.Unlock()
+	return v, nil
+}
+
+// lookup returns the value of id if it is not outdated. The caller must
+// hold the lock.
+func (c *ttlCache) lookup(id string) (interface{}, bool) {
+	e, ok := c.entries[id]
+	if !ok || (c.ttl > 0 && time.Since(e.time) >= c.ttl) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// put stores the value of id. The caller must hold the lock.
+func (c *ttlCache) put(id string, v interface{}) {
+	c.entries[id] = cacheEntry{value: v, time: time.Now()}
+}
+
+// drop removes the value of id, so it is read again. The caller must hold
+// the lock.
+func (c *ttlCache) drop(id string) {
+	delete(c.entries, id)
+}
+
+// keep removes the values of all ids which are not known anymore. The
+// caller must hold the lock.
+func (c *ttlCache) keep(known map[string]bool) {
+	for id := range c.entries {
+		if !known[id] {
+			delete(c.entries, id)
+		}
+	}
+}
+
+// refreshGuard lets only one refresh of a panel run at a time, so a slow
+// daemon does not pile up the refreshes.
+type refreshGuard struct {
+	busy bool
+}
+
+// start returns false if a refresh is already running. Otherwise the
+// caller must call done when the refresh is finished. The caller must not
+// hold the lock.
+func (g *refreshGuard) start() bool {
+	lock.Lock()
+	defer lock.Unlock()
+	if g.busy {
+		return false
+	}
+	g.busy = true
+	return true
+}
+
+// done finishes the refresh. The caller must not hold the lock.
+func (g *refreshGuard) done() {
+	lock.Lock()
+	g.busy = false
+	lock.Unlock()
+}
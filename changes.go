@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	ui "github.com/gizak/termui"
+	"github.com/samalba/dockerclient"
+)
+
+// the kinds of a filesystem change of a container
+const (
+	changeModified = 0
+	changeAdded    = 1
+	changeDeleted  = 2
+)
+
+var changeKinds = map[int]string{
+	changeModified: "M",
+	changeAdded:    "A",
+	changeDeleted:  "D",
+}
+
+// changeNode is a file or directory in the tree of the changes. The
+// counts contain the changes below the node, the change of the node itself
+// is its kind.
+type changeNode struct {
+	Path     string
+	Kind     int
+	Changed  bool
+	Children []*changeNode
+	Counts   map[int]int
+}
+
+// genChangeTree creates the tree of the changes. Directories which
+// contain changes but were not changed themselves are part of the tree,
+// too.
+func genChangeTree(changes []*dockerclient.ContainerChanges) *changeNode {
+	root := &changeNode{Path: "/", Counts: make(map[int]int)}
+	nodes := map[string]*changeNode{"/": root}
+	var node func(path string) *changeNode
+	node = func(path string) *changeNode {
+		if n, ok := nodes[path]; ok {
+			return n
+		}
+		n := &changeNode{Path: path, Counts: make(map[int]int)}
+		nodes[path] = n
+		parent := node(pathDir(path))
+		parent.Children = append(parent.Children, n)
+		return n
+	}
+	for _, c := range changes {
+		n := node(c.Path)
+		n.Kind = c.Kind
+		n.Changed = true
+		for p := pathDir(c.Path); ; p = pathDir(p) {
+			nodes[p].Counts[c.Kind]++
+			if p == "/" {
+				break
+			}
+		}
+	}
+	for _, n := range nodes {
+		sort.Sort(changesByPath(n.Children))
+	}
+	return root
+}
+
+// pathDir returns the parent directory of the absolute path.
+func pathDir(path string) string {
+	i := strings.LastIndex(strings.TrimSuffix(path, "/"), "/")
+	if i <= 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+type changesByPath []*changeNode
+
+func (s changesByPath) Len() int           { return len(s) }
+func (s changesByPath) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s changesByPath) Less(i, j int) bool { return s[i].Path < s[j].Path }
+
+// containerChanges shows the changes of the writable layer of the
+// selected container. The changes are only read when the panel is opened
+// or refreshed with 'r' because the daemon has to walk the filesystem.
+func containerChanges() (dockerDrawer, keyHandler, ui.GridBufferer) {
+	var (
+		tree      *changeNode
+		lineNodes []*changeNode
+		expanded  = make(map[string]bool)
+	)
+	list := newSelectList("Changes")
+
+	var addLines func(lines []string, n *changeNode, indent string) []string
+	addLines = func(lines []string, n *changeNode, indent string) []string {
+		for _, c := range n.Children {
+			if len(c.Children) == 0 {
+				lines = append(lines, fmt.Sprintf("%s    %s %s", indent, changeKinds[c.Kind], c.Path))
+				lineNodes = append(lineNodes, c)
+				continue
+			}
+			state := "+"
+			if expanded[c.Path] {
+				state = "-"
+			}
+			kind := " "
+			if c.Changed {
+				kind = changeKinds[c.Kind]
+			}
+			lines = append(lines, fmt.Sprintf("%s[%s] %s %-30s A %d  M %d  D %d", indent, state, kind, c.Path,
+				c.Counts[changeAdded], c.Counts[changeModified], c.Counts[changeDeleted]))
+			lineNodes = append(lineNodes, c)
+			if expanded[c.Path] {
+				lines = addLines(lines, c, indent+"    ")
+			}
+		}
+		return lines
+	}
+
+	update := func() {
+		lineNodes = nil
+		var lines []string
+		if tree != nil {
+			lines = addLines(nil, tree, "")
+		}
+		list.setLines(lines)
+	}
+
+	drawer := func(dc *dockerclient.DockerClient) {
+		lock.Lock()
+		id := containerDetailsID
+		name := selectedContainerName()
+		lock.Unlock()
+		tree = nil
+		if id != "" {
+			changes, err := dc.ContainerChanges(id)
+			if err != nil {
+				list.message(fmt.Sprintf("cannot read changes: %s", err))
+			} else {
+				tree = genChangeTree(changes)
+				list.message("")
+			}
+		}
+		list.label = fmt.Sprintf("Changes: %s (%s to expand, %s to refresh)", name, quoteKey(boundKey("list", "select")), quoteKey(boundKey("changes", "refresh")))
+		update()
+	}
+
+	keys := func(dc *dockerclient.DockerClient, key string) bool {
+		if list.handleKey(key) {
+			return true
+		}
+		switch key {
+		case "r":
+			drawer(dc)
+		case "<enter>":
+			if i, ok := list.selected(len(lineNodes)); ok && len(lineNodes[i].Children) > 0 {
+				n := lineNodes[i]
+				expanded[n.Path] = !expanded[n.Path]
+				update()
+			}
+		default:
+			return false
+		}
+		return true
+	}
+	return drawer, keys, list
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/samalba/dockerclient"
+)
+
+func TestPathDir(t *testing.T) {
+	tests := map[string]string{
+		"/":        "/",
+		"/a":       "/",
+		"/a/b":     "/a",
+		"/a/b/c":   "/a/b",
+		"/a/b/":    "/a",
+		"/etc/foo": "/etc",
+	}
+	for path, want := range tests {
+		if got := pathDir(path); got != want {
+			t.Errorf("pathDir(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestGenChangeTree(t *testing.T) {
+	root := genChangeTree([]*dockerclient.ContainerChanges{
+		{Path: "/d", Kind: changeDeleted},
+		{Path: "/a/b/c", Kind: changeAdded},
+		{Path: "/a", Kind: changeModified},
+		{Path: "/a/b/e", Kind: changeAdded},
+	})
+	if got := childPaths(root); !reflect.DeepEqual(got, []string{"/a", "/d"}) {
+		t.Fatalf("children of / = %v", got)
+	}
+	if want := map[int]int{changeModified: 1, changeAdded: 2, changeDeleted: 1}; !reflect.DeepEqual(root.Counts, want) {
+		t.Errorf("counts of / = %v, want %v", root.Counts, want)
+	}
+
+	a := root.Children[0]
+	if !a.Changed || a.Kind != changeModified {
+		t.Errorf("/a: changed %v kind %d", a.Changed, a.Kind)
+	}
+	// the counts only contain the changes below the node
+	if want := map[int]int{changeAdded: 2}; !reflect.DeepEqual(a.Counts, want) {
+		t.Errorf("counts of /a = %v, want %v", a.Counts, want)
+	}
+
+	// directories with changes below are part of the tree
+	b := a.Children[0]
+	if b.Path != "/a/b" || b.Changed {
+		t.Errorf("/a/b: path %s changed %v", b.Path, b.Changed)
+	}
+	if got := childPaths(b); !reflect.DeepEqual(got, []string{"/a/b/c", "/a/b/e"}) {
+		t.Errorf("children of /a/b = %v", got)
+	}
+	d := root.Children[1]
+	if !d.Changed || d.Kind != changeDeleted || len(d.Children) != 0 {
+		t.Errorf("/d: changed %v kind %d children %d", d.Changed, d.Kind, len(d.Children))
+	}
+}
+
+func TestGenChangeTreeEmpty(t *testing.T) {
+	root := genChangeTree(nil)
+	if len(root.Children) != 0 || len(root.Counts) != 0 {
+		t.Errorf("tree without changes: %d children, counts %v", len(root.Children), root.Counts)
+	}
+}
+
+func childPaths(n *changeNode) []string {
+	var res []string
+	for _, c := range n.Children {
+		res = append(res, c.Path)
+	}
+	return res
+}
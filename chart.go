@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	ui "github.com/gizak/termui"
+	"github.com/samalba/dockerclient"
+)
+
+const (
+	chartHeight     = 10
+	chartLabelWidth = 8
+	chartTimeFormat = "15:04:05"
+)
+
+// the dots of a braille character, from the top left to the bottom right
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// timeSeries is a named series of values. The values are aligned at the
+// newest value with the times of the chart.
+type timeSeries struct {
+	Label string
+	Color ui.Attribute
+	// the color of the label in the legend, ui.ColorDefault uses Color
+	LabelColor ui.Attribute
+	Values     []float64
+}
+
+// timeChart is a line chart of one or more series with a value axis which
+// starts at zero and a time axis. A legend is shown when there is more
+// than one series.
+type timeChart struct {
+	ui.Block
+	Series    []timeSeries
+	Times     []time.Time
+	Format    func(float64) string
+	AxesColor ui.Attribute
+}
+
+func newTimeChart(label string, format func(float64) string) *timeChart {
+	tc := &timeChart{Block: *ui.NewBlock(), Format: format}
+	tc.BorderLabel = label
+	tc.Height = chartHeight
+	tc.AxesColor = currentTheme.Axes
+	return tc
+}
+
+func (tc *timeChart) maxValue() float64 {
+	mx := 0.0
+	for _, s := range tc.Series {
+		for _, v := range s.Values {
+			if v > mx {
+				mx = v
+			}
+		}
+	}
+	if mx <= 0 {
+		mx = 1
+	}
+	return mx
+}
+
+// Buffer implements the Bufferer interface.
+func (tc *timeChart) Buffer() ui.Buffer {
+	buf := tc.Block.Buffer()
+
+	var (
+		x0     = tc.InnerX() + chartLabelWidth + 1
+		y0     = tc.InnerY()
+		width  = tc.InnerWidth() - chartLabelWidth - 1
+		height = tc.InnerHeight() - 2
+		top    = tc.maxValue()
+		points = 2 * width
+	)
+	if width < 2 || height < 1 {
+		return buf
+	}
+
+	// value axis
+	for y := y0; y < y0+height; y++ {
+		buf.Set(x0-1, y, ui.Cell{Ch: ui.VDASH, Fg: tc.AxesColor, Bg: tc.Bg})
+	}
+	buf.Set(x0-1, y0+height, ui.Cell{Ch: ui.ORIGIN, Fg: tc.AxesColor, Bg: tc.Bg})
+	for x := x0; x < x0+width; x++ {
+		buf.Set(x, y0+height, ui.Cell{Ch: ui.HDASH, Fg: tc.AxesColor, Bg: tc.Bg})
+	}
+	tc.setText(buf, tc.InnerX(), y0, tc.Format(top), chartLabelWidth, tc.AxesColor)
+	if height > 2 {
+		tc.setText(buf, tc.InnerX(), y0+height/2, tc.Format(top*float64(height-height/2)/float64(height)), chartLabelWidth, tc.AxesColor)
+	}
+	tc.setText(buf, tc.InnerX(), y0+height-1, tc.Format(0), chartLabelWidth, tc.AxesColor)
+
+	// time axis, labeled from the newest value to the left
+	for x := width - len(chartTimeFormat); x >= 0; x -= len(chartTimeFormat) + 2 {
+		idx := len(tc.Times) - (points - 2*x)
+		if idx < 0 {
+			break
+		}
+		tc.setText(buf, x0+x, y0+height+1, tc.Times[idx].Format(chartTimeFormat), len(chartTimeFormat), tc.AxesColor)
+	}
+
+	// the series as braille dots
+	cells := make(map[[2]int]rune)
+	colors := make(map[[2]int]ui.Attribute)
+	dots := height * 4
+	for _, s := range tc.Series {
+		vals := s.Values
+		if len(vals) > points {
+			vals = vals[len(vals)-points:]
+		}
+		prev := -1
+		for i, v := range vals {
+			px := points - len(vals) + i
+			py := int(v / top * float64(dots-1))
+			if py < 0 {
+				py = 0
+			}
+			if py > dots-1 {
+				py = dots - 1
+			}
+			from, to := py, py
+			if prev >= 0 && prev < py {
+				from = prev + 1
+			} else if prev > py {
+				to = prev - 1
+			}
+			for y := from; y <= to; y++ {
+				cell := [2]int{x0 + px/2, y0 + height - 1 - y/4}
+				cells[cell] |= brailleDots[3-y%4][px%2]
+				colors[cell] = s.Color
+			}
+			prev = py
+		}
+	}
+	for c, ch := range cells {
+		buf.Set(c[0], c[1], ui.Cell{Ch: 0x2800 + ch, Fg: colors[c], Bg: tc.Bg})
+	}
+
+	// legend
+	if len(tc.Series) > 1 {
+		x := x0 + width
+		for i := len(tc.Series) - 1; i >= 0; i-- {
+			s := tc.Series[i]
+			x -= len(s.Label) + 3
+			lclr := s.LabelColor
+			if lclr == ui.ColorDefault {
+				lclr = s.Color
+			}
+			tc.setText(buf, x, y0, "■ ", 2, s.Color)
+			tc.setText(buf, x+2, y0, s.Label, len(s.Label), lclr)
+		}
+	}
+	return buf
+}
+
+func (tc *timeChart) setText(buf ui.Buffer, x, y int, s string, maxlen int, fg ui.Attribute) {
+	rs := []rune(s)
+	if len(rs) > maxlen {
+		rs = rs[:maxlen]
+	}
+	for i, r := range rs {
+		buf.Set(x+i, y, ui.Cell{Ch: r, Fg: fg, Bg: tc.Bg})
+	}
+}
+
+// historyPanel shows the history of the containers either with the given
+// sparkline widget, as time charts with labeled axes or in the compact
+// view with one line per container.
+type historyPanel struct {
+	pager
+	spark  ui.GridBufferer
+	list   *ui.List
+	charts []*timeChart
+	lines  bool
+	// the groups of the sparklines, bars or charts
+	groups []*containerGroup
+	x      int
+	y      int
+	width  int
+}
+
+func newHistoryPanel(spark ui.GridBufferer) *historyPanel {
+	list := ui.NewList()
+	list.ItemFgColor = currentTheme.Items
+	return &historyPanel{spark: spark, list: list}
+}
+
+// reset removes the charts and lines of the last update.
+func (p *historyPanel) reset() {
+	p.charts = nil
+	p.groups = nil
+	p.list.Items = nil
+	p.list.Height = 2
+}
+
+func (p *historyPanel) toggle() {
+	p.lines = !p.lines
+}
+
+func (p *historyPanel) addChart(label string, data []int, times []time.Time, color ui.Attribute, format func(float64) string) {
+	tc := newTimeChart(label, format)
+	tc.Series = []timeSeries{{Label: label, Color: color, Values: intsToFloats(data)}}
+	tc.Times = times
+	p.charts = append(p.charts, tc)
+}
+
+// GetHeight implements the GridBufferer interface.
+func (p *historyPanel) GetHeight() int {
+	if p.compact() {
+		return p.list.GetHeight()
+	}
+	if !p.lines {
+		return p.spark.GetHeight()
+	}
+	h := 0
+	for _, c := range p.charts {
+		h += c.Height
+	}
+	if h == 0 {
+		return 2
+	}
+	return h
+}
+
+// SetX implements the GridBufferer interface.
+func (p *historyPanel) SetX(x int) {
+	p.x = x
+	p.spark.SetX(x)
+	p.list.SetX(x)
+}
+
+// SetY implements the GridBufferer interface.
+func (p *historyPanel) SetY(y int) {
+	p.y = y
+	p.spark.SetY(y)
+	p.list.SetY(y)
+}
+
+// SetWidth implements the GridBufferer interface.
+func (p *historyPanel) SetWidth(w int) {
+	p.width = w
+	p.spark.SetWidth(w)
+	p.list.SetWidth(w)
+}
+
+// Buffer implements the Bufferer interface.
+func (p *historyPanel) Buffer() ui.Buffer {
+	if p.compact() {
+		return p.list.Buffer()
+	}
+	if !p.lines {
+		return p.spark.Buffer()
+	}
+	buf := ui.NewBuffer()
+	y := p.y
+	for _, c := range p.charts {
+		c.X = p.x
+		c.Y = y
+		c.Width = p.width
+		buf.Merge(c.Buffer())
+		y += c.Height
+	}
+	return buf
+}
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// miniSparkline draws the newest values of the data as a single line of
+// the given width.
+func miniSparkline(data []int, width int) string {
+	if len(data) > width {
+		data = data[len(data)-width:]
+	}
+	mx := findMaxInt(data)
+	res := make([]rune, width)
+	for i := range res {
+		res[i] = ' '
+	}
+	for i, v := range data {
+		r := sparkRunes[0]
+		if mx > 0 && v > 0 {
+			r = sparkRunes[v*(len(sparkRunes)-1)/mx]
+		}
+		res[width-len(data)+i] = r
+	}
+	return string(res)
+}
+
+func intsToFloats(vals []int) []float64 {
+	res := make([]float64, len(vals))
+	for i, v := range vals {
+		res[i] = float64(v)
+	}
+	return res
+}
+
+// genTimes returns the times of the values which are derived from two
+// consecutive stats.
+func genTimes(stats []*dockerclient.Stats) []time.Time {
+	var res []time.Time
+	for i := range stats {
+		if i > 0 {
+			res = append(res, stats[i].Read)
+		}
+	}
+	return res
+}
+
+// genGroupTimes returns the times of the member with the longest history.
+func genGroupTimes(g *containerGroup) []time.Time {
+	var res []time.Time
+	for _, m := range g.Members {
+		if t := genTimes(statsData[allcontainers[m].Id]); len(t) > len(res) {
+			res = t
+		}
+	}
+	return res
+}
+
+func formatPercent(v float64) string {
+	return fmt.Sprintf("%d%%", int(v))
+}
+
+func formatBytes(v float64) string {
+	return memAsString(uint64(v))
+}
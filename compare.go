@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+
+	ui "github.com/gizak/termui"
+	"github.com/samalba/dockerclient"
+)
+
+const (
+	maxMarkedContainers = 4
+	compareChartHeight  = 14
+)
+
+var (
+	// the ids of the containers which are compared, in the order in
+	// which they were marked
+	markedContainers []string
+)
+
+func containerMarked(id string) bool {
+	for _, m := range markedContainers {
+		if m == id {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneMarkedContainers unmarks the containers which are gone. The caller
+// must hold the lock.
+func pruneMarkedContainers() {
+	var res []string
+	for _, id := range markedContainers {
+		for _, c := range allcontainers {
+			if c.Id == id {
+				res = append(res, id)
+			}
+		}
+	}
+	markedContainers = res
+}
+
+// markListRow marks or unmarks the container under the cursor of the
+// container list for the comparison.
+func markListRow(list *ui.List) error {
+	lock.Lock()
+	defer lock.Unlock()
+	if listCursor >= len(listRows) || listRows[listCursor].group != nil {
+		return fmt.Errorf("no container selected")
+	}
+	id := allcontainers[listRows[listCursor].index].Id
+	for i, m := range markedContainers {
+		if m == id {
+			markedContainers = append(markedContainers[:i], markedContainers[i+1:]...)
+			updateContainerList(list)
+			return nil
+		}
+	}
+	if len(markedContainers) >= maxMarkedContainers {
+		return fmt.Errorf("at most %d containers can be compared", maxMarkedContainers)
+	}
+	markedContainers = append(markedContainers, id)
+	updateContainerList(list)
+	return nil
+}
+
+// containerCompare overlays the metrics of the marked containers.
+func containerCompare() (dockerDrawer, []ui.GridBufferer) {
+	cpu := newTimeChart("CPU %", formatPercent)
+	mem := newTimeChart("Memory", formatBytes)
+	rx := newTimeChart("Network rx", formatBytes)
+	tx := newTimeChart("Network tx", formatBytes)
+	charts := []*timeChart{cpu, mem, rx, tx}
+	gens := []func([]*dockerclient.Stats) []int{
+		genCPUSystemUsage,
+		genMemory,
+		func(stats []*dockerclient.Stats) []int { return genNetwork(stats, rxDiffer) },
+		func(stats []*dockerclient.Stats) []int { return genNetwork(stats, txDiffer) },
+	}
+	var widgets []ui.GridBufferer
+	for _, c := range charts {
+		c.Height = compareChartHeight
+		widgets = append(widgets, c)
+	}
+	return func(dc *dockerclient.DockerClient) {
+		lock.Lock()
+		defer lock.Unlock()
+		for _, c := range charts {
+			c.Series = nil
+			c.Times = nil
+		}
+		for i, id := range markedContainers {
+			stats, ok := statsData[id]
+			if !ok {
+				continue
+			}
+			times := genTimes(stats)
+			hclr, _ := healthColorByID(id)
+			for j, c := range charts {
+				c.Series = append(c.Series, timeSeries{
+					Label:      containerNameByID(id),
+					Color:      currentTheme.Series[i%len(currentTheme.Series)],
+					LabelColor: hclr,
+					Values:     intsToFloats(gens[j](stats)),
+				})
+				if len(times) > len(c.Times) {
+					c.Times = times
+				}
+			}
+		}
+	}, widgets
+}
+
+// comparePanel creates the grid with the charts of the comparison in two
+// rows.
+func comparePanel(title ui.GridBufferer, charts []ui.GridBufferer) *ui.Grid {
+	p := &ui.Grid{}
+
+	p.AddRows(
+		ui.NewRow(
+			ui.NewCol(12, 0, title)),
+		ui.NewRow(
+			ui.NewCol(6, 0, charts[0]),
+			ui.NewCol(6, 0, charts[1])),
+		ui.NewRow(
+			ui.NewCol(6, 0, charts[2]),
+			ui.NewCol(6, 0, charts[3])))
+
+	return p
+}
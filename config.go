@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+)
+
+var configFile = flag.String("config", filepath.Join(os.Getenv("HOME"), ".dockmon.json"), "the configuration file")
+
+// config is the content of the configuration file.
+type config struct {
+	Layouts    []layoutConfig    `json:"layouts"`
+	Theme      string            `json:"theme"`
+	Thresholds thresholdsConfig  `json:"thresholds"`
+	Keys       map[string]string `json:"keys"`
+}
+
+// loadConfig reads the configuration file. A missing file is no error,
+// the defaults are used then.
+func loadConfig(fn string) (*config, error) {
+	cfg := &config{Thresholds: defaultThresholds}
+	f, err := os.Open(fn)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
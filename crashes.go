@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samalba/dockerclient"
+)
+
+const (
+	maxExits          = 5
+	restartLoopWindow = time.Minute
+	restartLoopExits  = 3
+)
+
+var crashes = make(map[string]*crashInfo)
+
+type exitInfo struct {
+	Time      time.Time
+	ExitCode  int
+	OOMKilled bool
+}
+
+// crashInfo contains the OOM kills and exits of a container which are
+// seen in the events or the inspect data.
+type crashInfo struct {
+	OOMs      int
+	OOMKilled bool
+	Exits     []exitInfo
+}
+
+// crashInfoFor returns the crash info of the container. The caller must
+// hold the lock.
+func crashInfoFor(id string) *crashInfo {
+	ci, ok := crashes[id]
+	if !ok {
+		ci = &crashInfo{}
+		crashes[id] = ci
+	}
+	return ci
+}
+
+// addExit adds an exit which is not known yet. The exits are ordered by
+// their time.
+func (ci *crashInfo) addExit(e exitInfo) {
+	i := len(ci.Exits)
+	for ; i > 0 && !ci.Exits[i-1].Time.Before(e.Time); i-- {
+		if ci.Exits[i-1].Time.Equal(e.Time) {
+			return
+		}
+	}
+	ci.Exits = append(ci.Exits[:i], append([]exitInfo{e}, ci.Exits[i:]...)...)
+	if len(ci.Exits) > maxExits {
+		ci.Exits = ci.Exits[1:]
+	}
+}
+
+// restartLoop returns true if the container exited several times within
+// a short time.
+func (ci *crashInfo) restartLoop(now time.Time) bool {
+	n := 0
+	for _, e := range ci.Exits {
+		if now.Sub(e.Time) < restartLoopWindow {
+			n++
+		}
+	}
+	return n >= restartLoopExits
+}
+
+// inspectCrashes updates the crash info of the container with its inspect
+// data. The last exit is recorded even if the container is running again,
+// a container with a restart policy is usually restarted before it is
+// inspected.
+func inspectCrashes(dc *dockerclient.DockerClient, id string) {
+	cix, err := inspectContainerExt(dc, id)
+	if err != nil {
+		return
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	ci := crashInfoFor(id)
+	ci.OOMKilled = cix.State.OOMKilled
+	if !cix.State.FinishedAt.IsZero() {
+		ci.addExit(exitInfo{Time: cix.State.FinishedAt, ExitCode: cix.State.ExitCode, OOMKilled: cix.State.OOMKilled})
+	}
+}
+
+// recordCrashEvent counts the OOM kills of a container. When a container
+// dies, its exit code is read from the inspect data. The crash info of a
+// removed container is dropped.
+func recordCrashEvent(dc *dockerclient.DockerClient, e dockerclient.Event) {
+	switch eventType(e) {
+	case "oom":
+		lock.Lock()
+		crashInfoFor(e.Id).OOMs++
+		lock.Unlock()
+	case "die":
+		go inspectCrashes(dc, e.Id)
+	case "destroy":
+		lock.Lock()
+		delete(crashes, e.Id)
+		lock.Unlock()
+	}
+}
+
+// genCrashBadge returns a short marker for containers which were OOM
+// killed or are restarting. The caller must hold the lock.
+func genCrashBadge(c dockerclient.Container) string {
+	var badges []string
+	ci, ok := crashes[c.Id]
+	if ok {
+		ooms := ci.OOMs
+		if ooms == 0 && ci.OOMKilled {
+			ooms = 1
+		}
+		if ooms > 0 {
+			badges = append(badges, fmt.Sprintf("OOM×%d", ooms))
+		}
+	}
+	if strings.HasPrefix(c.Status, "Restarting") || (ok && ci.restartLoop(time.Now())) {
+		badges = append(badges, "restarting")
+	}
+	return strings.Join(badges, " ")
+}
+
+// genCrashes returns the OOM kills and last exits of the container for
+// the details.
+func genCrashes(id string) []string {
+	lock.Lock()
+	defer lock.Unlock()
+	ci, ok := crashes[id]
+	if !ok {
+		return nil
+	}
+	res := []string{fmt.Sprintf("OOM kills: %d", ci.OOMs)}
+	for i := len(ci.Exits) - 1; i >= 0; i-- {
+		e := ci.Exits[i]
+		prefix := "Exits:"
+		if i < len(ci.Exits)-1 {
+			prefix = "      "
+		}
+		s := fmt.Sprintf("%s %d at %s", prefix, e.ExitCode, e.Time.Local().Format("2006-01-02 15:04:05"))
+		if e.OOMKilled {
+			s += " (OOM killed)"
+		}
+		res = append(res, s)
+	}
+	return res
+}
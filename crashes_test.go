@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddExit(t *testing.T) {
+	t0 := time.Date(2017, 1, 16, 9, 0, 0, 0, time.UTC)
+	at := func(secs ...int) []exitInfo {
+		var res []exitInfo
+		for _, s := range secs {
+			res = append(res, exitInfo{Time: t0.Add(time.Duration(s) * time.Second), ExitCode: s})
+		}
+		return res
+	}
+	tests := []struct {
+		name  string
+		exits []exitInfo
+		want  []int
+	}{
+		{"ordered", at(1, 2, 3), []int{1, 2, 3}},
+		{"out of order", at(3, 1, 2), []int{1, 2, 3}},
+		{"duplicates", at(1, 2, 2, 1, 3, 3), []int{1, 2, 3}},
+		{"limit keeps the newest", at(1, 2, 3, 4, 5, 6, 7), []int{3, 4, 5, 6, 7}},
+		{"old exit beyond the limit", at(2, 3, 4, 5, 6, 1), []int{2, 3, 4, 5, 6}},
+	}
+	for _, tt := range tests {
+		ci := &crashInfo{}
+		for _, e := range tt.exits {
+			ci.addExit(e)
+		}
+		var got []int
+		for _, e := range ci.Exits {
+			got = append(got, e.ExitCode)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: exits %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: exits %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestRestartLoop(t *testing.T) {
+	now := time.Date(2017, 1, 16, 9, 0, 0, 0, time.UTC)
+	ci := &crashInfo{}
+	ci.addExit(exitInfo{Time: now.Add(-2 * time.Hour)})
+	ci.addExit(exitInfo{Time: now.Add(-30 * time.Second)})
+	ci.addExit(exitInfo{Time: now.Add(-20 * time.Second)})
+	if ci.restartLoop(now) {
+		t.Error("two recent exits are a restart loop")
+	}
+	ci.addExit(exitInfo{Time: now.Add(-10 * time.Second)})
+	if !ci.restartLoop(now) {
+		t.Error("three recent exits are no restart loop")
+	}
+}
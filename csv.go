@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samalba/dockerclient"
+)
+
+const (
+	csvBackups = 3
+	// the wide csv files are named dockmon-<time>.csv
+	csvWideName       = "dockmon"
+	csvSegmentPattern = "20060102-150405"
+)
+
+var (
+	csvDir      = flag.String("csv", "", "write the container statistics as csv files to this directory")
+	csvWide     = flag.Bool("csv-wide", false, "write one csv row per tick for all containers instead of one file per container")
+	csvMaxSize  = flag.Int64("csv-maxsize", 10*mb, "rotate a csv file when it grows beyond this number of bytes")
+	csvFiles    = make(map[string]*csvFile)
+	csvWideFile *csvFile
+	csvColumns  = []string{"cpu_percent", "mem_usage", "mem_limit", "rx_per_sec", "tx_per_sec", "blkio_read_per_sec", "blkio_write_per_sec"}
+	// guards the csv files and csvErr, the files are written without
+	// holding the global lock
+	csvLock sync.Mutex
+	// the error of the last write, it is shown in the title
+	csvErr error
+)
+
+// csvFile is a csv file which is rotated when it gets too big. The
+// header is written at the top of every new file.
+type csvFile struct {
+	path   string
+	header []string
+	f      *os.File
+	w      *csv.Writer
+}
+
+func openCSVFile(path string, header []string) (*csvFile, error) {
+	cf := &csvFile{path: path, header: header}
+	if err := cf.open(); err != nil {
+		return nil, err
+	}
+	return cf, nil
+}
+
+func (cf *csvFile) open() error {
+	f, err := os.OpenFile(cf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	cf.f = f
+	cf.w = csv.NewWriter(f)
+	if fi.Size() == 0 {
+		return cf.flushed(cf.header)
+	}
+	return nil
+}
+
+func (cf *csvFile) flushed(rec []string) error {
+	if err := cf.w.Write(rec); err != nil {
+		return err
+	}
+	cf.w.Flush()
+	return cf.w.Error()
+}
+
+func (cf *csvFile) write(rec []string) error {
+	if err := cf.flushed(rec); err != nil {
+		return err
+	}
+	fi, err := cf.f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() > *csvMaxSize {
+		return cf.rotate()
+	}
+	return nil
+}
+
+// rotate renames the current file to path.1, path.1 to path.2 and so on
+// and starts a new file.
+func (cf *csvFile) rotate() error {
+	cf.close()
+	for i := csvBackups - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", cf.path, i), fmt.Sprintf("%s.%d", cf.path, i+1))
+	}
+	if err := os.Rename(cf.path, cf.path+".1"); err != nil {
+		return err
+	}
+	return cf.open()
+}
+
+func (cf *csvFile) close() {
+	cf.w.Flush()
+	cf.f.Close()
+}
+
+func csvSampleRecord(s sample) []string {
+	return []string{
+		fmt.Sprintf("%d", s.CPUPercent),
+		fmt.Sprintf("%d", s.MemUsage),
+		fmt.Sprintf("%d", s.MemLimit),
+		fmt.Sprintf("%d", s.RxBytes),
+		fmt.Sprintf("%d", s.TxBytes),
+		fmt.Sprintf("%d", s.BlkioRead),
+		fmt.Sprintf("%d", s.BlkioWrite),
+	}
+}
+
+// writeContainerCSV appends the sample of the given container to its csv
+// file.
+func writeContainerCSV(id, name string, s sample) {
+	csvLock.Lock()
+	defer csvLock.Unlock()
+	cf, ok := csvFiles[id]
+	if !ok {
+		var err error
+		cf, err = openCSVFile(filepath.Join(*csvDir, name+".csv"), append([]string{"timestamp"}, csvColumns...))
+		if err != nil {
+			csvErr = err
+			return
+		}
+		csvFiles[id] = cf
+	}
+	csvErr = cf.write(append([]string{s.Read.Format(time.RFC3339)}, csvSampleRecord(s)...))
+}
+
+// closeStaleCSV closes the csv files of containers which are gone.
+func closeStaleCSV(running map[string][]*dockerclient.Stats) {
+	csvLock.Lock()
+	defer csvLock.Unlock()
+	for id, cf := range csvFiles {
+		if _, ok := running[id]; !ok {
+			cf.close()
+			delete(csvFiles, id)
+		}
+	}
+}
+
+// writeWideCSV writes one row with the newest samples of all containers.
+// The fields of containers without a sample are empty. When the set of
+// containers changes, a new file segment with the new columns is started,
+// so every file has one header.
+func writeWideCSV(now time.Time) {
+	header := []string{"timestamp"}
+	row := []string{now.Format(time.RFC3339)}
+	lock.Lock()
+	for _, c := range allcontainers {
+		for _, col := range csvColumns {
+			header = append(header, containerName(c)+"."+col)
+		}
+		if s, ok := lastSample(statsData[c.Id]); ok {
+			row = append(row, csvSampleRecord(s)...)
+		} else {
+			row = append(row, make([]string, len(csvColumns))...)
+		}
+	}
+	lock.Unlock()
+
+	csvLock.Lock()
+	defer csvLock.Unlock()
+	if csvWideFile != nil && strings.Join(csvWideFile.header, ",") != strings.Join(header, ",") {
+		csvWideFile.close()
+		csvWideFile = nil
+	}
+	if csvWideFile == nil {
+		cf, err := openCSVFile(wideCSVPath(*csvDir, now), header)
+		if err != nil {
+			csvErr = err
+			return
+		}
+		csvWideFile = cf
+	}
+	csvErr = csvWideFile.write(row)
+}
+
+// wideCSVPath returns the path of the wide csv segment started at t.
+func wideCSVPath(dir string, t time.Time) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.csv", csvWideName, t.Format(csvSegmentPattern)))
+}
+
+// csvError returns the error of the last csv write.
+func csvError() error {
+	csvLock.Lock()
+	defer csvLock.Unlock()
+	return csvErr
+}
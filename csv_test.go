@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samalba/dockerclient"
+)
+
+func readCSV(t *testing.T, path string) [][]string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	recs, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return recs
+}
+
+func TestCSVRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dockmon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(size int64) { *csvMaxSize = size }(*csvMaxSize)
+	*csvMaxSize = 100
+
+	path := filepath.Join(dir, "web.csv")
+	header := []string{"timestamp", "value"}
+	cf, err := openCSVFile(path, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cf.close()
+	// every row is 20 bytes, so every fifth row rotates the file
+	for i := 0; i < 30; i++ {
+		if err := cf.write([]string{fmt.Sprintf("%012d", i), "123456"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, p := range []string{path, path + ".1", path + ".2", path + ".3"} {
+		recs := readCSV(t, p)
+		if len(recs) == 0 || strings.Join(recs[0], ",") != "timestamp,value" {
+			t.Errorf("%s does not start with the header: %v", p, recs)
+		}
+	}
+	if _, err := os.Stat(path + ".4"); !os.IsNotExist(err) {
+		t.Errorf("more than %d backups are kept", csvBackups)
+	}
+	if fi, err := os.Stat(path + ".1"); err != nil || fi.Size() <= *csvMaxSize {
+		t.Errorf("the rotated file is not full: %v", err)
+	}
+}
+
+func TestCSVAppend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dockmon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "web.csv")
+	header := []string{"timestamp", "value"}
+	for i := 0; i < 2; i++ {
+		cf, err := openCSVFile(path, header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf.write([]string{"t", fmt.Sprint(i)})
+		cf.close()
+	}
+	// the header is only written to a new file
+	if recs := readCSV(t, path); len(recs) != 3 {
+		t.Errorf("appended file: %v", recs)
+	}
+}
+
+func TestWideCSVSegments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dockmon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(d string) { *csvDir = d }(*csvDir)
+	*csvDir = dir
+	defer func() {
+		if csvWideFile != nil {
+			csvWideFile.close()
+			csvWideFile = nil
+		}
+		allcontainers = nil
+	}()
+
+	t0 := time.Date(2017, 1, 16, 9, 0, 0, 0, time.UTC)
+	web := dockerclient.Container{Id: "1", Names: []string{"/web"}}
+	db := dockerclient.Container{Id: "2", Names: []string{"/db"}}
+	allcontainers = []dockerclient.Container{web}
+	writeWideCSV(t0)
+	writeWideCSV(t0.Add(time.Second))
+	allcontainers = []dockerclient.Container{web, db}
+	writeWideCSV(t0.Add(2 * time.Second))
+	if err := csvError(); err != nil {
+		t.Fatal(err)
+	}
+
+	first := readCSV(t, wideCSVPath(dir, t0))
+	if len(first) != 3 || len(first[0]) != 1+len(csvColumns) {
+		t.Errorf("first segment: %v", first)
+	}
+	second := readCSV(t, wideCSVPath(dir, t0.Add(2*time.Second)))
+	if len(second) != 2 || len(second[0]) != 1+2*len(csvColumns) || second[0][1] != "web.cpu_percent" {
+		t.Errorf("second segment: %v", second)
+	}
+	// containers without a sample have empty fields
+	if len(second) == 2 && second[1][1] != "" {
+		t.Errorf("the fields of a container without a sample are %v", second[1])
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+
+	ui "github.com/gizak/termui"
+	"github.com/samalba/dockerclient"
+)
+
+const dashboardChartHeight = 12
+
+// dashboard shows the retained history of a single container as charts
+// together with its current values.
+type dashboard struct {
+	cpu     *timeChart
+	mem     *timeChart
+	netw    *timeChart
+	blkio   *timeChart
+	current *ui.List
+}
+
+func newDashboard() *dashboard {
+	d := &dashboard{
+		cpu:     newTimeChart("CPU %", formatPercent),
+		mem:     newTimeChart("Memory", formatBytes),
+		netw:    newTimeChart("Network", formatBytes),
+		blkio:   newTimeChart("Block IO", formatBytes),
+		current: ui.NewList(),
+	}
+	for _, c := range d.charts() {
+		c.Height = dashboardChartHeight
+	}
+	d.current.BorderLabel = "Current"
+	d.current.ItemFgColor = currentTheme.Items
+	d.current.Height = 2
+	return d
+}
+
+func (d *dashboard) charts() []*timeChart {
+	return []*timeChart{d.cpu, d.mem, d.netw, d.blkio}
+}
+
+// update shows the given container and its stats. Without a container
+// the dashboard is cleared.
+func (d *dashboard) update(ci *dockerclient.ContainerInfo, stats []*dockerclient.Stats) {
+	if ci == nil {
+		for _, c := range d.charts() {
+			c.Series = nil
+			c.Times = nil
+		}
+		d.current.Items = nil
+		d.current.Height = 2
+		return
+	}
+	times := genTimes(stats)
+	for _, c := range d.charts() {
+		c.Times = times
+	}
+	d.cpu.Series = []timeSeries{
+		{Label: "cpu", Color: currentTheme.CPU, Values: intsToFloats(genCPUSystemUsage(stats))},
+	}
+	d.mem.Series = []timeSeries{
+		{Label: "usage", Color: currentTheme.Memory, Values: intsToFloats(genMemory(stats))},
+	}
+	d.netw.Series = []timeSeries{
+		{Label: "rx", Color: currentTheme.Rx, Values: intsToFloats(genNetwork(stats, rxDiffer))},
+		{Label: "tx", Color: currentTheme.Tx, Values: intsToFloats(genNetwork(stats, txDiffer))},
+	}
+	d.blkio.Series = []timeSeries{
+		{Label: "read", Color: currentTheme.BlkioRead, Values: intsToFloats(genBlkio(stats, "Read"))},
+		{Label: "write", Color: currentTheme.BlkioWrite, Values: intsToFloats(genBlkio(stats, "Write"))},
+	}
+	d.current.Items = genCurrentValues(ci, stats)
+	d.current.Height = len(d.current.Items) + 2
+}
+
+// genCurrentValues shows the newest values of the container against its
+// limits.
+func genCurrentValues(ci *dockerclient.ContainerInfo, stats []*dockerclient.Stats) []string {
+	s, ok := lastSample(stats)
+	if !ok {
+		return []string{"no stats yet"}
+	}
+	cpuLimit := "no limit"
+	if ci.HostConfig != nil && ci.HostConfig.CpuQuota > 0 && ci.HostConfig.CpuPeriod > 0 {
+		cpuLimit = fmt.Sprintf("limit %.2f cpus", float64(ci.HostConfig.CpuQuota)/float64(ci.HostConfig.CpuPeriod))
+	}
+	return []string{
+		fmt.Sprintf("CPU: %d%% (%s)", s.CPUPercent, cpuLimit),
+		fmt.Sprintf("Memory: %s of %s (%d%%)", memAsString(s.MemUsage), memAsString(s.MemLimit), s.memPercent()),
+		fmt.Sprintf("Rx: %s/s", memAsString(s.RxBytes)),
+		fmt.Sprintf("Tx: %s/s", memAsString(s.TxBytes)),
+		fmt.Sprintf("Blkio read: %s/s", memAsString(s.BlkioRead)),
+		fmt.Sprintf("Blkio write: %s/s", memAsString(s.BlkioWrite)),
+	}
+}
+
+// dashboardPanel creates the grid with the charts of the dashboard on the
+// left and the current values and details on the right.
+func dashboardPanel(title ui.GridBufferer, d *dashboard, details ui.GridBufferer) *ui.Grid {
+	p := &ui.Grid{}
+
+	p.AddRows(
+		ui.NewRow(
+			ui.NewCol(12, 0, title)),
+		ui.NewRow(
+			ui.NewCol(7, 0, d.cpu, d.mem, d.netw, d.blkio),
+			ui.NewCol(5, 0, d.current, details)))
+
+	return p
+}
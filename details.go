@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samalba/dockerclient"
+)
+
+var collapsedSections = make(map[string]bool)
+
+// detailSection is a collapsible section of the container details.
+type detailSection struct {
+	Title string
+	Lines []string
+}
+
+// genDetailSections creates the sections of the container details. The
+// extended inspect data may be nil if the daemon does not provide it.
+func genDetailSections(ci *dockerclient.ContainerInfo, cix *containerInspect) []detailSection {
+	var sections []detailSection
+
+	general := []string{
+		fmt.Sprintf("Name: %s", ci.Name),
+		fmt.Sprintf("Id: %s", ci.Id),
+		fmt.Sprintf("Image: %s", ci.Image),
+	}
+	if ci.State != nil {
+		general = append(general,
+			fmt.Sprintf("State: %s", ci.State.StateString()),
+			fmt.Sprintf("Uptime: %s", ci.State.String()),
+			fmt.Sprintf("Exit code: %d", ci.State.ExitCode))
+	}
+	general = append(general,
+		fmt.Sprintf("Created: %s", ci.Created),
+		fmt.Sprintf("Path: %s", ci.Path),
+		fmt.Sprintf("Args: %s", ci.Args),
+		fmt.Sprintf("Command: %s", genCommand(ci)),
+		fmt.Sprintf("Hostname: %s", ci.Config.Hostname))
+	sections = append(sections, detailSection{"General", general})
+
+	restart := []string{fmt.Sprintf("Policy: %s", genRestartPolicy(ci))}
+	if cix != nil {
+		restart = append(restart, fmt.Sprintf("Restart count: %d", cix.RestartCount))
+	}
+	restart = append(restart, genCrashes(ci.Id)...)
+	sections = append(sections, detailSection{"Restarts", restart})
+
+	if cix != nil && cix.State.Health != nil {
+		sections = append(sections, detailSection{"Health", genHealth(cix)})
+	}
+
+	resources := []string{
+		fmt.Sprintf("Memory: %d", ci.Config.Memory),
+		fmt.Sprintf("Swap: %d", ci.Config.MemorySwap),
+		fmt.Sprintf("Cpu-Shares: %d", ci.Config.CpuShares),
+		fmt.Sprintf("Cpu-Set: %s", ci.Config.Cpuset),
+	}
+	if ci.HostConfig != nil {
+		for _, u := range ci.HostConfig.Ulimits {
+			resources = append(resources, fmt.Sprintf("Ulimit %s: soft %d hard %d", u.Name, u.Soft, u.Hard))
+		}
+	}
+	sections = append(sections, detailSection{"Resources", resources})
+
+	network := []string{
+		fmt.Sprintf("IP: %s", ci.NetworkSettings.IPAddress),
+		fmt.Sprintf("Ports: %s", genPortMappings(ci)),
+	}
+	if cix != nil {
+		network = append(network, genNetworks(cix)...)
+	}
+	sections = append(sections, detailSection{"Networks", network})
+
+	var mounts []string
+	if cix != nil && len(cix.Mounts) > 0 {
+		mounts = genMounts(cix)
+	} else {
+		mounts = genVolumes(ci)
+	}
+	sections = append(sections, detailSection{"Mounts", mounts})
+
+	sections = append(sections, detailSection{"Labels", genLabels(ci.Config.Labels)})
+
+	if ci.HostConfig != nil {
+		logging := []string{fmt.Sprintf("Driver: %s", ci.HostConfig.LogConfig.Type)}
+		logging = append(logging, genLabels(ci.HostConfig.LogConfig.Config)...)
+		sections = append(sections, detailSection{"Logging", logging})
+		sections = append(sections, detailSection{"Security", genSecurity(ci.HostConfig)})
+	}
+
+	if !*hideEnv {
+		env := ci.Config.Env
+		if !secretsRevealed() {
+			env = maskEnv(env)
+		}
+		sections = append(sections, detailSection{"Environment", env})
+	}
+	return sections
+}
+
+// genDetailLines creates the lines of the details with a header for
+// every section. The content of collapsed sections is hidden. The second
+// return value contains the section of every line.
+func genDetailLines(sections []detailSection) ([]string, []string) {
+	var (
+		lines  []string
+		titles []string
+	)
+	for _, s := range sections {
+		state := "-"
+		if collapsedSections[s.Title] {
+			state = "+"
+		}
+		lines = append(lines, fmt.Sprintf("[%s] %s", state, s.Title))
+		titles = append(titles, s.Title)
+		if collapsedSections[s.Title] {
+			continue
+		}
+		for _, l := range s.Lines {
+			lines = append(lines, "    "+l)
+			titles = append(titles, s.Title)
+		}
+	}
+	return lines, titles
+}
+
+func genCommand(ci *dockerclient.ContainerInfo) string {
+	cmd := append(append([]string{}, ci.Config.Entrypoint...), ci.Config.Cmd...)
+	if len(cmd) == 0 {
+		cmd = append([]string{ci.Path}, ci.Args...)
+	}
+	return strings.Join(cmd, " ")
+}
+
+func genRestartPolicy(ci *dockerclient.ContainerInfo) string {
+	if ci.HostConfig == nil || ci.HostConfig.RestartPolicy.Name == "" {
+		return "no"
+	}
+	p := ci.HostConfig.RestartPolicy
+	if p.Name == "on-failure" {
+		return fmt.Sprintf("%s (max %d retries)", p.Name, p.MaximumRetryCount)
+	}
+	return p.Name
+}
+
+func genLabels(labels map[string]string) []string {
+	var res []string
+	for k, v := range labels {
+		res = append(res, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(res)
+	return res
+}
+
+func genMounts(ci *containerInspect) []string {
+	var res []string
+	for _, m := range ci.Mounts {
+		src := m.Source
+		if m.Type == "volume" && m.Name != "" {
+			src = m.Name
+		}
+		rw := "ro"
+		if m.RW {
+			rw = "rw"
+		}
+		mode := rw
+		if m.Mode != "" {
+			mode = fmt.Sprintf("%s,%s", rw, m.Mode)
+		}
+		res = append(res, fmt.Sprintf("%s -> %s (%s, %s)", src, m.Destination, m.Type, mode))
+	}
+	return res
+}
+
+func genSecurity(hc *dockerclient.HostConfig) []string {
+	res := []string{
+		fmt.Sprintf("Privileged: %t", hc.Privileged),
+		fmt.Sprintf("Read-only rootfs: %t", hc.ReadonlyRootfs),
+	}
+	if len(hc.CapAdd) > 0 {
+		res = append(res, fmt.Sprintf("Cap-Add: %s", strings.Join(hc.CapAdd, ",")))
+	}
+	if len(hc.CapDrop) > 0 {
+		res = append(res, fmt.Sprintf("Cap-Drop: %s", strings.Join(hc.CapDrop, ",")))
+	}
+	for _, o := range hc.SecurityOpt {
+		res = append(res, fmt.Sprintf("Security-Opt: %s", o))
+	}
+	return res
+}
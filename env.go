@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"time"
+)
+
+const (
+	revealDuration = 10 * time.Second
+	maskedValue    = "********"
+)
+
+var (
+	maskPatterns = flag.String("mask", "PASSWORD,SECRET,TOKEN,KEY,CREDENTIAL", "mask the values of environment variables whose names contain one of these comma separated patterns")
+	hideEnv      = flag.Bool("no-env", false, "don't show the environment of containers")
+	revealUntil  time.Time
+)
+
+// secretKey returns true if the name of the environment variable matches
+// one of the mask patterns.
+func secretKey(key string) bool {
+	key = strings.ToUpper(key)
+	for _, p := range strings.Split(*maskPatterns, ",") {
+		p = strings.ToUpper(strings.TrimSpace(p))
+		if p != "" && strings.Contains(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskEnv replaces the values of secret environment variables.
+func maskEnv(env []string) []string {
+	var res []string
+	for _, e := range env {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) == 2 && secretKey(kv[0]) {
+			e = kv[0] + "=" + maskedValue
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// revealSecrets shows the values of secret environment variables for a
+// short time.
+func revealSecrets() {
+	revealUntil = time.Now().Add(revealDuration)
+}
+
+func secretsRevealed() bool {
+	return time.Now().Before(revealUntil)
+}
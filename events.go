@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	ui "github.com/gizak/termui"
+	"github.com/samalba/dockerclient"
+)
+
+const maxEvents = 1000
+
+var (
+	dockerEvents   []dockerclient.Event
+	containerNames = make(map[string]string)
+	eventTypes     = []string{"", "create", "start", "restart", "die", "kill", "oom", "stop", "destroy", "health_status"}
+)
+
+// eventColors returns the colors of the event types in the current theme.
+func eventColors() map[string]ui.Attribute {
+	return map[string]ui.Attribute{
+		"create":        currentTheme.Good,
+		"start":         currentTheme.Good,
+		"restart":       currentTheme.Warning,
+		"die":           currentTheme.Critical,
+		"kill":          currentTheme.Critical,
+		"oom":           currentTheme.Critical | ui.AttrBold,
+		"stop":          currentTheme.Neutral,
+		"destroy":       currentTheme.Neutral,
+		"health_status": currentTheme.Info,
+	}
+}
+
+// eventType returns the type of the event without its details, e.g.
+// "health_status" for "health_status: unhealthy".
+func eventType(e dockerclient.Event) string {
+	return strings.TrimSpace(strings.SplitN(e.Status, ":", 2)[0])
+}
+
+// startEventMonitor records the events of the docker daemon. When the
+// connection is lost, it reconnects.
+func startEventMonitor(dc *dockerclient.DockerClient) {
+	go func() {
+		for {
+			stop := make(chan struct{})
+			evts, err := dc.MonitorEvents(nil, stop)
+			if err == nil {
+				for e := range evts {
+					if e.Error != nil {
+						break
+					}
+					recordEvent(e.Event)
+					recordCrashEvent(dc, e.Event)
+				}
+			}
+			close(stop)
+			time.Sleep(5 * time.Second)
+		}
+	}()
+}
+
+func recordEvent(e dockerclient.Event) {
+	lock.Lock()
+	defer lock.Unlock()
+	dockerEvents = append(dockerEvents, e)
+	if len(dockerEvents) > maxEvents {
+		dockerEvents = dockerEvents[1:]
+	}
+	switch eventType(e) {
+	case "create":
+		containerMounts.drop(e.Id)
+	case "start":
+		containerStarts.drop(e.Id)
+	case "destroy":
+		containerStarts.drop(e.Id)
+		containerMounts.drop(e.Id)
+	}
+}
+
+// rememberContainerNames keeps the names of the running containers so
+// events of containers which are gone can still be named. The caller must
+// hold the lock.
+func rememberContainerNames() {
+	for _, c := range allcontainers {
+		containerNames[c.Id] = containerName(c)
+	}
+}
+
+func eventContainerName(id string) string {
+	if n, ok := containerNames[id]; ok {
+		return n
+	}
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// genEventLine returns the line of an event, colored by its type. The name
+// of a running container is colored by its health. The caller must hold
+// the lock.
+func genEventLine(e dockerclient.Event) string {
+	at := time.Unix(e.Time, 0).Format("15:04:05")
+	name := fmt.Sprintf("%-30s", eventContainerName(e.Id))
+	rest := fmt.Sprintf("%-25s %s", e.Status, e.From)
+	clr, typed := eventColors()[eventType(e)]
+	hclr, healthy := healthColorByID(e.Id)
+	if !healthy {
+		s := fmt.Sprintf("%s %s %s", at, name, rest)
+		if typed {
+			return fmt.Sprintf("[%s](%s)", s, markup(clr))
+		}
+		return s
+	}
+	name = fmt.Sprintf("[%s](%s)", name, markup(hclr))
+	if typed {
+		at = fmt.Sprintf("[%s](%s)", at, markup(clr))
+		rest = fmt.Sprintf("[%s](%s)", rest, markup(clr))
+	}
+	return fmt.Sprintf("%s %s %s", at, name, rest)
+}
+
+// eventTimeline shows the events of the docker daemon, the newest at the
+// top. The events can be filtered by the selected container and by type.
+func eventTimeline() (dockerDrawer, keyHandler, ui.GridBufferer) {
+	var (
+		offset     = 0
+		typeFilter = 0
+		byCnt      = false
+	)
+	list := ui.NewList()
+	list.ItemFgColor = currentTheme.Fg
+
+	drawer := func(dc *dockerclient.DockerClient) {
+		lock.Lock()
+		defer lock.Unlock()
+		filter := "all events"
+		if byCnt {
+			filter = selectedContainerName()
+		}
+		if typeFilter > 0 {
+			filter = fmt.Sprintf("%s, %s", filter, eventTypes[typeFilter])
+		}
+		var lines []string
+		for i := len(dockerEvents) - 1; i >= 0; i-- {
+			e := dockerEvents[i]
+			if byCnt && e.Id != containerDetailsID {
+				continue
+			}
+			if typeFilter > 0 && eventType(e) != eventTypes[typeFilter] {
+				continue
+			}
+			lines = append(lines, genEventLine(e))
+		}
+		visible := ui.TermHeight() - 5
+		if visible < 1 {
+			visible = 1
+		}
+		if offset > len(lines)-visible {
+			offset = len(lines) - visible
+		}
+		if offset < 0 {
+			offset = 0
+		}
+		lines = lines[offset:]
+		if len(lines) > visible {
+			lines = lines[:visible]
+		}
+		list.BorderLabel = fmt.Sprintf("Events: %s (%s container, %s type)", filter, quoteKey(boundKey("events", "filter-container")), quoteKey(boundKey("events", "filter-type")))
+		list.Items = lines
+		list.Height = len(lines) + 2
+	}
+
+	keys := func(dc *dockerclient.DockerClient, key string) bool {
+		switch key {
+		case "<up>":
+			offset--
+		case "<down>":
+			offset++
+		case "c":
+			byCnt = !byCnt
+			offset = 0
+		case "t":
+			typeFilter = (typeFilter + 1) % len(eventTypes)
+			offset = 0
+		default:
+			return false
+		}
+		drawer(dc)
+		return true
+	}
+	return drawer, keys, list
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	ui "github.com/gizak/termui"
+	"github.com/samalba/dockerclient"
+)
+
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+)
+
+var (
+	groupBy        = flag.String("group-by", "", "group the containers by 'compose', 'image' or 'label=<name>'")
+	groupKey       = composeGroupKey
+	groupView      = false
+	expandedGroups = make(map[string]bool)
+	listRows       []listRow
+	listCursor     = 0
+	listOffset     = 0
+	// the memory of the docker host, 0 if it is not known
+	hostMemory uint64
+)
+
+// containerGroup is a set of containers which belong together, e.g. the
+// replicas of a compose service or the containers of a team.
+type containerGroup struct {
+	Name    string
+	Members []int
+}
+
+// listRow is a row of the container list. It is either the header of a
+// group or a single container.
+type listRow struct {
+	group *containerGroup
+	index int
+}
+
+type groupKeyFunc func(c dockerclient.Container) string
+
+// parseGroupBy returns the key function for the value of the -group-by
+// flag. Without a value the containers are grouped by compose project.
+func parseGroupBy(by string) (groupKeyFunc, error) {
+	switch {
+	case by == "" || by == "compose":
+		return composeGroupKey, nil
+	case by == "image":
+		return func(c dockerclient.Container) string {
+			return c.Image
+		}, nil
+	case strings.HasPrefix(by, "label="):
+		label := strings.TrimPrefix(by, "label=")
+		if label == "" {
+			return nil, fmt.Errorf("missing label name in group-by value: %s", by)
+		}
+		return func(c dockerclient.Container) string {
+			return c.Labels[label]
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown group-by value: %s", by)
+}
+
+func composeGroupKey(c dockerclient.Container) string {
+	project, ok := c.Labels[composeProjectLabel]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", project, c.Labels[composeServiceLabel])
+}
+
+// genGroups groups the containers by the given key. Containers with an
+// empty key do not belong to a group. The groups are ordered by their
+// first member.
+func genGroups(containers []dockerclient.Container, key groupKeyFunc) []*containerGroup {
+	var res []*containerGroup
+	groups := make(map[string]*containerGroup)
+	for i, c := range containers {
+		k := key(c)
+		if k == "" {
+			continue
+		}
+		g, ok := groups[k]
+		if !ok {
+			g = &containerGroup{Name: k}
+			groups[k] = g
+			res = append(res, g)
+		}
+		g.Members = append(g.Members, i)
+	}
+	return res
+}
+
+// groupSample sums up the newest samples of all members of the group.
+// Members without a memory limit report the memory of the host as their
+// limit, so the summed up limit is capped at the memory of the host.
+func groupSample(g *containerGroup) sample {
+	var res sample
+	for _, i := range g.Members {
+		s, _ := lastSample(statsData[allcontainers[i].Id])
+		res = res.add(s)
+	}
+	if hostMemory > 0 && res.MemLimit > hostMemory {
+		res.MemLimit = hostMemory
+	}
+	return res
+}
+
+func (g *containerGroup) label() string {
+	return fmt.Sprintf("%s (%d)", g.Name, len(g.Members))
+}
+
+// panelGroups returns the groups which are shown in the panels. In the
+// group view, containers without a group are shown as a group with a
+// single member, otherwise every container is its own group.
+func panelGroups() []*containerGroup {
+	var res []*containerGroup
+	grouped := make(map[int]*containerGroup)
+	if groupView {
+		for _, g := range genGroups(allcontainers, groupKey) {
+			for _, m := range g.Members {
+				grouped[m] = g
+			}
+		}
+	}
+	for i, c := range allcontainers {
+		g, ok := grouped[i]
+		if !ok {
+			res = append(res, &containerGroup{Name: containerName(c), Members: []int{i}})
+		} else if g.Members[0] == i {
+			res = append(res, g)
+		}
+	}
+	return res
+}
+
+// genGroupSeries sums up the series of all members of the group. The
+// series are aligned at their newest value.
+func genGroupSeries(g *containerGroup, gen func([]*dockerclient.Stats) []int) []int {
+	var res []int
+	for _, m := range g.Members {
+		series := gen(statsData[allcontainers[m].Id])
+		if len(series) > len(res) {
+			res = append(make([]int, len(series)-len(res)), res...)
+		}
+		off := len(res) - len(series)
+		for i, v := range series {
+			res[off+i] += v
+		}
+	}
+	return res
+}
+
+// groupHealthColor returns the health color of a single container. The
+// second return value is false for groups and containers without a
+// healthcheck.
+func groupHealthColor(g *containerGroup) (ui.Attribute, bool) {
+	if len(g.Members) != 1 {
+		return 0, false
+	}
+	return healthColor(allcontainers[g.Members[0]])
+}
+
+func genGroupListName(g *containerGroup) string {
+	state := "+"
+	if expandedGroups[g.Name] {
+		state = "-"
+	}
+	s := groupSample(g)
+	return fmt.Sprintf("[%s] %s (%d) %d%% %s rx %s tx %s", state, g.Name, len(g.Members), s.CPUPercent,
+		memAsString(s.MemUsage), memAsString(s.RxBytes), memAsString(s.TxBytes))
+}
+
+// genListRows creates the rows of the container list. A group is shown at
+// the position of its first member, the members are only shown when the
+// group is expanded.
+func genListRows() []listRow {
+	var rows []listRow
+	groupOf := make(map[int]*containerGroup)
+	for _, g := range genGroups(allcontainers, groupKey) {
+		for _, m := range g.Members {
+			groupOf[m] = g
+		}
+	}
+	for i := range allcontainers {
+		g, ok := groupOf[i]
+		if !ok {
+			rows = append(rows, listRow{index: i})
+			continue
+		}
+		if g.Members[0] != i {
+			continue
+		}
+		rows = append(rows, listRow{group: g})
+		if expandedGroups[g.Name] {
+			for _, m := range g.Members {
+				rows = append(rows, listRow{index: m})
+			}
+		}
+	}
+	return rows
+}
+
+// updateContainerList fills the list with the rows of the container list
+// and highlights the row under the cursor. The list is scrolled so that
+// the cursor is visible. The caller must hold the lock.
+func updateContainerList(list *ui.List) {
+	listRows = genListRows()
+	if listCursor >= len(listRows) {
+		listCursor = len(listRows) - 1
+	}
+	if listCursor < 0 {
+		listCursor = 0
+	}
+	visible := maxPanelHeight(list) - 2
+	if listCursor < listOffset {
+		listOffset = listCursor
+	}
+	if listCursor >= listOffset+visible {
+		listOffset = listCursor - visible + 1
+	}
+	if listOffset > len(listRows)-visible {
+		listOffset = len(listRows) - visible
+	}
+	if listOffset < 0 {
+		listOffset = 0
+	}
+	end := listOffset + visible
+	if end > len(listRows) {
+		end = len(listRows)
+	}
+	list.BorderLabel = containerListLabel
+	if listOffset > 0 || end < len(listRows) {
+		list.BorderLabel = fmt.Sprintf("%s %d-%d of %d", containerListLabel, listOffset+1, end, len(listRows))
+	}
+	var items []string
+	for i := listOffset; i < end; i++ {
+		r := listRows[i]
+		var s string
+		if r.group != nil {
+			s = genGroupListName(r.group)
+		} else {
+			s = genContainerListName(r.index, allcontainers[r.index], 30)
+			if containerMarked(allcontainers[r.index].Id) {
+				s = "* " + s
+			}
+			if groupedRow(r) {
+				s = "    " + s
+			}
+		}
+		badge := ""
+		if r.group == nil {
+			badge = genCrashBadge(allcontainers[r.index])
+		}
+		switch {
+		case i == listCursor:
+			s = highlight(strings.TrimSpace(s + " " + badge))
+		case r.group == nil:
+			s = colorByHealth(allcontainers[r.index], s)
+			if badge != "" {
+				s = fmt.Sprintf("%s [%s](%s)", s, badge, markup(currentTheme.Critical))
+			}
+		}
+		items = append(items, s)
+	}
+	list.Items = items
+	list.Height = len(items) + 2
+}
+
+func groupedRow(r listRow) bool {
+	return groupKey(allcontainers[r.index]) != ""
+}
+
+// moveListCursor moves the cursor of the container list by delta rows. A
+// container under the cursor becomes the selected container.
+func moveListCursor(list *ui.List, delta int) {
+	lock.Lock()
+	defer lock.Unlock()
+	listCursor += delta
+	updateContainerList(list)
+	if len(listRows) > 0 && listRows[listCursor].group == nil {
+		containerDetailsIndex = listRows[listCursor].index
+	}
+}
+
+// selectListRow expands or collapses the group under the cursor. If the
+// cursor is on a container, its index is returned.
+func selectListRow(list *ui.List) (int, bool) {
+	lock.Lock()
+	defer lock.Unlock()
+	if listCursor >= len(listRows) {
+		return 0, false
+	}
+	r := listRows[listCursor]
+	if r.group == nil {
+		return r.index, true
+	}
+	expandedGroups[r.group.Name] = !expandedGroups[r.group.Name]
+	updateContainerList(list)
+	return 0, false
+}
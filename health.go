@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	ui "github.com/gizak/termui"
+	"github.com/samalba/dockerclient"
+)
+
+const maxProbes = 3
+
+// healthColors returns the colors of the health states in the current
+// theme.
+func healthColors() map[string]ui.Attribute {
+	return map[string]ui.Attribute{
+		"healthy":   currentTheme.Good,
+		"unhealthy": currentTheme.Critical,
+		"starting":  currentTheme.Warning,
+	}
+}
+
+// containerHealth returns the health of a container with a healthcheck
+// from its status, e.g. "Up 5 minutes (unhealthy)".
+func containerHealth(c dockerclient.Container) string {
+	switch {
+	case strings.HasSuffix(c.Status, "(healthy)"):
+		return "healthy"
+	case strings.HasSuffix(c.Status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.HasSuffix(c.Status, "(health: starting)"):
+		return "starting"
+	}
+	return ""
+}
+
+// healthColor returns the color for the name of the container. The second
+// return value is false if the container has no healthcheck.
+func healthColor(c dockerclient.Container) (ui.Attribute, bool) {
+	clr, ok := healthColors()[containerHealth(c)]
+	return clr, ok
+}
+
+// healthColorByID returns the health color of the running container with
+// the given id. The caller must hold the lock.
+func healthColorByID(id string) (ui.Attribute, bool) {
+	for _, c := range allcontainers {
+		if c.Id == id {
+			return healthColor(c)
+		}
+	}
+	return 0, false
+}
+
+// colorByHealth colors the given text by the health of the container.
+func colorByHealth(c dockerclient.Container, s string) string {
+	if clr, ok := healthColor(c); ok {
+		return fmt.Sprintf("[%s](%s)", s, markup(clr))
+	}
+	return s
+}
+
+// genHealth returns the health of the container and the output of the
+// last probes for the details.
+func genHealth(ci *containerInspect) []string {
+	h := ci.State.Health
+	if h == nil {
+		return nil
+	}
+	res := []string{fmt.Sprintf("Health: %s (failing streak %d)", h.Status, h.FailingStreak)}
+	probes := h.Log
+	if len(probes) > maxProbes {
+		probes = probes[len(probes)-maxProbes:]
+	}
+	for i := len(probes) - 1; i >= 0; i-- {
+		p := probes[i]
+		prefix := "Probes:"
+		if i < len(probes)-1 {
+			prefix = "       "
+		}
+		output := strings.Join(strings.Fields(p.Output), " ")
+		res = append(res, fmt.Sprintf("%s %s exit %d: %s", prefix, p.End.Local().Format("15:04:05"), p.ExitCode, output))
+	}
+	return res
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	ui "github.com/gizak/termui"
+	"github.com/samalba/dockerclient"
+)
+
+// containerImages caches the image ids of the containers. The container
+// list only contains the image reference the container was started with.
+var containerImages = make(map[string]string)
+
+// updateContainerImages reads the image ids of the containers which are
+// not cached yet.
+func updateContainerImages(dc *dockerclient.DockerClient) {
+	lock.Lock()
+	var missing []string
+	running := make(map[string]bool)
+	for _, c := range allcontainers {
+		running[c.Id] = true
+		if _, ok := containerImages[c.Id]; !ok {
+			missing = append(missing, c.Id)
+		}
+	}
+	for id := range containerImages {
+		if !running[id] {
+			delete(containerImages, id)
+		}
+	}
+	lock.Unlock()
+	for _, id := range missing {
+		if ci, err := inspectContainerExt(dc, id); err == nil {
+			lock.Lock()
+			containerImages[id] = ci.Image
+			lock.Unlock()
+		}
+	}
+}
+
+// imageList shows the images of the docker host and the running
+// containers which use them. Unused images can be removed.
+func imageList() (dockerDrawer, keyHandler, ui.GridBufferer) {
+	var (
+		images []*dockerclient.Image
+		failed bool
+	)
+	list := newSelectList(fmt.Sprintf("Images (%s/%s to remove an unused image)", quoteKey(boundKey("list", "select")), quoteKey(boundKey("images", "remove"))))
+
+	drawer := func(dc *dockerclient.DockerClient) {
+		imgs, err := dc.ListImages(false)
+		if err != nil {
+			lock.Lock()
+			list.message(fmt.Sprintf("cannot read images: %s", err))
+			failed = true
+			lock.Unlock()
+			return
+		}
+		sort.Sort(imagesByCreated(imgs))
+		updateContainerImages(dc)
+		lock.Lock()
+		defer lock.Unlock()
+		if failed {
+			list.msg = ""
+			failed = false
+		}
+		images = imgs
+		var lines []string
+		for _, img := range images {
+			lines = append(lines, genImageListName(img, imageUsers(img, allcontainers)))
+		}
+		list.setLines(lines)
+	}
+
+	keys := func(dc *dockerclient.DockerClient, key string) bool {
+		if list.handleKey(key) {
+			return true
+		}
+		if key != "d" && key != "<enter>" {
+			return false
+		}
+		// the image is resolved now, the list is sorted again on every
+		// refresh
+		lock.Lock()
+		i, ok := list.selected(len(images))
+		if !ok {
+			lock.Unlock()
+			return true
+		}
+		img := images[i]
+		users := imageUsers(img, allcontainers)
+		lock.Unlock()
+		if len(users) > 0 {
+			list.message(fmt.Sprintf("%s is used by %s", imageName(img), strings.Join(users, ", ")))
+			return true
+		}
+		list.ask(fmt.Sprintf("Remove image %s?", imageName(img)), func() string {
+			if _, err := dc.RemoveImage(img.Id, false); err != nil {
+				return fmt.Sprintf("cannot remove %s: %s", imageName(img), err)
+			}
+			drawer(dc)
+			return fmt.Sprintf("removed %s", imageName(img))
+		})
+		return true
+	}
+	return drawer, keys, list
+}
+
+type imagesByCreated []*dockerclient.Image
+
+func (s imagesByCreated) Len() int           { return len(s) }
+func (s imagesByCreated) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s imagesByCreated) Less(i, j int) bool { return s[i].Created > s[j].Created }
+
+func danglingImage(img *dockerclient.Image) bool {
+	return len(img.RepoTags) == 0 || (len(img.RepoTags) == 1 && img.RepoTags[0] == "<none>:<none>")
+}
+
+func shortImageID(img *dockerclient.Image) string {
+	id := strings.TrimPrefix(img.Id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func imageName(img *dockerclient.Image) string {
+	if danglingImage(img) {
+		return shortImageID(img)
+	}
+	return img.RepoTags[0]
+}
+
+// imageUsers returns the names of the containers which run the image. The
+// containers are matched by the id of their image. If the id is not known
+// yet, a container references its image by a tag, a tag without the
+// implicit "latest" or by the (short) id. The caller must hold the lock.
+func imageUsers(img *dockerclient.Image, containers []dockerclient.Container) []string {
+	var res []string
+	imgID := strings.TrimPrefix(img.Id, "sha256:")
+	for _, c := range containers {
+		var used bool
+		if id, ok := containerImages[c.Id]; ok {
+			used = strings.TrimPrefix(id, "sha256:") == imgID
+		} else {
+			used = c.Image == img.Id || (len(c.Image) >= 12 && strings.HasPrefix(imgID, c.Image))
+			for _, t := range img.RepoTags {
+				if c.Image == t || c.Image+":latest" == t {
+					used = true
+				}
+			}
+		}
+		if used {
+			res = append(res, containerName(c))
+		}
+	}
+	return res
+}
+
+func imageSize(img *dockerclient.Image) uint64 {
+	// older daemons report the size including the parent layers as
+	// virtual size
+	if img.VirtualSize > img.Size {
+		return uint64(img.VirtualSize)
+	}
+	return uint64(img.Size)
+}
+
+func genImageListName(img *dockerclient.Image, users []string) string {
+	tags := strings.Join(img.RepoTags, ",")
+	if danglingImage(img) {
+		tags = "<dangling>"
+	}
+	used := "unused"
+	if len(users) > 0 {
+		used = strings.Join(users, ",")
+	}
+	return fmt.Sprintf("%s %-40s %8s %s %s", shortImageID(img), tags, memAsString(imageSize(img)),
+		time.Unix(img.Created, 0).Format("2006-01-02 15:04"), used)
+}
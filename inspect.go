@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samalba/dockerclient"
+)
+
+// containerInspect contains the inspect data of a container which is not
+// part of dockerclient.ContainerInfo. The client requests an old API
+// version, so these fields are read with the API version of the daemon.
+type containerInspect struct {
+	Id              string
+	Name            string
+	Image           string
+	RestartCount    int
+	State           containerState
+	Mounts          []containerMount
+	Volumes         map[string]string
+	NetworkSettings struct {
+		Networks map[string]containerEndpoint
+	}
+}
+
+type containerState struct {
+	Status     string
+	Running    bool
+	Restarting bool
+	OOMKilled  bool
+	ExitCode   int
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Health     *containerHealthState
+}
+
+type containerHealthState struct {
+	Status        string
+	FailingStreak int
+	Log           []healthProbe
+}
+
+type healthProbe struct {
+	Start    time.Time
+	End      time.Time
+	ExitCode int
+	Output   string
+}
+
+type containerEndpoint struct {
+	NetworkID   string
+	IPAddress   string
+	IPPrefixLen int
+	Gateway     string
+	MacAddress  string
+	Aliases     []string
+}
+
+type containerMount struct {
+	Type        string
+	Name        string
+	Source      string
+	Destination string
+	Driver      string
+	Mode        string
+	RW          bool
+}
+
+// containerStarts caches the time when the containers were started last.
+// The time of a container is dropped when it is started again.
+var containerStarts = newTTLCache(0)
+
+// containerStartTime returns the time when the container was started
+// last. The caller must not hold the lock.
+func containerStartTime(dc *dockerclient.DockerClient, id string) (time.Time, error) {
+	t, err := containerStarts.get(id, func() (interface{}, error) {
+		ci, err := inspectContainerExt(dc, id)
+		if err != nil {
+			return nil, err
+		}
+		return ci.State.StartedAt, nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.(time.Time), nil
+}
+
+func inspectContainerExt(dc *dockerclient.DockerClient, id string) (*containerInspect, error) {
+	resp, err := dc.HTTPClient.Get(fmt.Sprintf("%s/containers/%s/json", dc.URL.String(), id))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot inspect container %s: %s", id, resp.Status)
+	}
+	var ci containerInspect
+	if err := json.NewDecoder(resp.Body).Decode(&ci); err != nil {
+		return nil, err
+	}
+	return &ci, nil
+}
+
+// usesVolume returns true if the container mounts the given volume.
+func (ci *containerInspect) usesVolume(v *dockerclient.Volume) bool {
+	for _, m := range ci.Mounts {
+		if m.Name == v.Name || (m.Source != "" && m.Source == v.Mountpoint) {
+			return true
+		}
+	}
+	for _, hostpath := range ci.Volumes {
+		if hostpath == v.Mountpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// genNetworks returns the networks of the container with the address,
+// gateway and aliases of the container in each network.
+func genNetworks(ci *containerInspect) []string {
+	var res []string
+	var keys []string
+	for n := range ci.NetworkSettings.Networks {
+		keys = append(keys, n)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		ep := ci.NetworkSettings.Networks[k]
+		s := fmt.Sprintf("%s: %s/%d gateway %s", k, ep.IPAddress, ep.IPPrefixLen, ep.Gateway)
+		if len(ep.Aliases) > 0 {
+			s += fmt.Sprintf(" aliases %s", strings.Join(ep.Aliases, ","))
+		}
+		res = append(res, s)
+	}
+	return res
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// keyBinding binds a key to an action of a panel. The bindings of the
+// "list" panel are used by all lists, global bindings have no panel.
+type keyBinding struct {
+	Panel  string
+	Action string
+	Key    string
+	Help   string
+}
+
+var keyBindings = []keyBinding{
+	{"", "help", "?", "show the key bindings"},
+	{"", "quit", "q", "close the panel, quit in the main panel"},
+	{"list", "up", "<up>", "move the cursor up"},
+	{"list", "down", "<down>", "move the cursor down"},
+	{"list", "select", "<enter>", "show the details of the container or expand the group"},
+	{"list", "confirm", "y", "confirm the question, any other key cancels"},
+	{"list", "page-up", "<previous>", "move the cursor one page up"},
+	{"list", "page-down", "<next>", "move the cursor one page down"},
+	{"", "compact", "z", "toggle the compact view with one line per container"},
+	{"", "layout", "<tab>", "switch to the next layout"},
+	{"", "groups", "g", "toggle the group view"},
+	{"", "snapshot", "s", "write a snapshot report"},
+	{"", "images", "i", "show the images"},
+	{"", "volumes", "v", "show the volumes"},
+	{"", "networks", "n", "show the networks"},
+	{"", "changes", "f", "show the file changes of the selected container"},
+	{"", "events", "e", "show the events"},
+	{"", "table", "t", "show the table of all containers"},
+	{"", "mark", "m", "mark the container for the comparison"},
+	{"", "compare", "c", "compare the marked containers"},
+	{"", "cpu-chart", "C", "toggle line charts of the CPU"},
+	{"", "memory-chart", "M", "toggle line charts of the memory"},
+	{"", "network-chart", "N", "toggle line charts of the network"},
+	{"", "blkio-chart", "B", "toggle line charts of the block IO"},
+	{"details", "reveal", "r", "reveal the secrets for ten seconds"},
+	{"images", "remove", "d", "remove the unused image"},
+	{"volumes", "remove", "d", "remove the orphan volume"},
+	{"networks", "connect", "c", "connect the selected container"},
+	{"networks", "disconnect", "x", "disconnect the selected container"},
+	{"changes", "refresh", "r", "read the changes again"},
+	{"table", "sort-prev", "<left>", "sort by the previous column"},
+	{"table", "sort-next", "<right>", "sort by the next column"},
+	{"table", "reverse", "o", "reverse the order"},
+	{"events", "filter-container", "c", "show only the events of the selected container"},
+	{"events", "filter-type", "t", "cycle through the event types"},
+}
+
+// the keys of the bindings which are remapped in the configuration, by
+// the id of the binding
+var boundKeys = make(map[string]string)
+
+// id returns the id of the binding, e.g. "images.remove" or "help" for a
+// global binding.
+func (b keyBinding) id() string {
+	if b.Panel == "" {
+		return b.Action
+	}
+	return b.Panel + "." + b.Action
+}
+
+// setKeys remaps the keys of the given bindings. A binding is given by its
+// id or by the name of its action, which remaps the action in all panels.
+// An id wins over the name of its action. Two actions of a panel or of a
+// panel and the lists must not be bound to the same key.
+func setKeys(keys map[string]string) error {
+	bound := make(map[string]string)
+	for _, byID := range []bool{false, true} {
+		for name, key := range keys {
+			if strings.Contains(name, ".") != byID {
+				continue
+			}
+			found := false
+			for _, b := range keyBindings {
+				if b.id() == name || (!byID && b.Action == name) {
+					bound[b.id()] = key
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("unknown action: %s", name)
+			}
+		}
+	}
+	if err := checkKeys(bound); err != nil {
+		return err
+	}
+	boundKeys = bound
+	return nil
+}
+
+// checkKeys returns an error if two actions of a panel are bound to the
+// same key with the given remapped keys. The bindings of the lists are
+// part of every panel but the global one.
+func checkKeys(bound map[string]string) error {
+	for _, b := range keyBindings {
+		for _, o := range keyBindings {
+			if o.id() >= b.id() || b.boundIn(bound) != o.boundIn(bound) {
+				continue
+			}
+			if b.Panel == o.Panel || (b.Panel != "" && o.Panel == "list") || (o.Panel != "" && b.Panel == "list") {
+				return fmt.Errorf("%s and %s are both bound to %s", o.id(), b.id(), b.boundIn(bound))
+			}
+		}
+	}
+	return nil
+}
+
+func (b keyBinding) bound() string {
+	return b.boundIn(boundKeys)
+}
+
+// boundIn returns the key of the binding with the given remapped keys.
+func (b keyBinding) boundIn(bound map[string]string) string {
+	if k, ok := bound[b.id()]; ok {
+		return k
+	}
+	return b.Key
+}
+
+// boundKey returns the key of the action of the panel.
+func boundKey(panel, action string) string {
+	for _, b := range keyBindings {
+		if b.Panel == panel && b.Action == action {
+			return b.bound()
+		}
+	}
+	return ""
+}
+
+// translateKey translates a pressed key to the default key of its action
+// in the first of the given panels which binds it, so the handlers only
+// deal with the default keys. A default key which is remapped to another
+// key is dropped.
+func translateKey(key string, panels ...string) string {
+	for _, p := range panels {
+		for _, b := range keyBindings {
+			if b.Panel == p && b.bound() == key {
+				return b.Key
+			}
+		}
+		for _, b := range keyBindings {
+			if b.Panel == p && b.Key == key {
+				return ""
+			}
+		}
+	}
+	return key
+}
+
+// genHelp lists the bindings of the given panel, of the lists and the
+// global bindings.
+func genHelp(panel string) []string {
+	var res []string
+	panels := []string{"list", ""}
+	if panel != "" {
+		panels = append([]string{panel}, panels...)
+	}
+	for _, p := range panels {
+		for _, b := range keyBindings {
+			if b.Panel == p {
+				res = append(res, fmt.Sprintf("%-10s %-24s %s", b.bound(), b.id(), b.Help))
+			}
+		}
+	}
+	if panel == "" {
+		res = append(res, fmt.Sprintf("%-10s %-24s %s", "0-9", "", "show the details of the container with the index"))
+	}
+	return res
+}
+
+// quoteKey quotes a key for the labels, e.g. 'r' or enter.
+func quoteKey(key string) string {
+	if strings.HasPrefix(key, "<") {
+		return strings.Trim(key, "<>")
+	}
+	return fmt.Sprintf("'%s'", key)
+}
@@ -0,0 +1,92 @@
+package main
+
+import "testing"
+
+func TestSetKeys(t *testing.T) {
+	defer setKeys(nil)
+	tests := []struct {
+		keys  map[string]string
+		err   bool
+		panel string
+		want  map[string]string
+	}{
+		{keys: nil, panel: "images", want: map[string]string{"remove": "d"}},
+		{keys: map[string]string{"images.remove": "D"}, panel: "images", want: map[string]string{"remove": "D"}},
+		{keys: map[string]string{"images.remove": "D"}, panel: "volumes", want: map[string]string{"remove": "d"}},
+		{keys: map[string]string{"remove": "D"}, panel: "volumes", want: map[string]string{"remove": "D"}},
+		{keys: map[string]string{"remove": "D", "volumes.remove": "X"}, panel: "images", want: map[string]string{"remove": "D"}},
+		{keys: map[string]string{"remove": "D", "volumes.remove": "X"}, panel: "volumes", want: map[string]string{"remove": "X"}},
+		{keys: map[string]string{"quit": "h"}, panel: "", want: map[string]string{"quit": "h"}},
+		{keys: map[string]string{"unknown": "x"}, err: true},
+		{keys: map[string]string{"images.unknown": "x"}, err: true},
+		// two actions of a panel
+		{keys: map[string]string{"table.reverse": "<left>"}, err: true},
+		// an action of a panel and of the lists
+		{keys: map[string]string{"images.remove": "<enter>"}, err: true},
+		{keys: map[string]string{"up": "x"}, err: true},
+		// a panel may shadow a global key
+		{keys: map[string]string{"events.filter-type": "g"}, panel: "events", want: map[string]string{"filter-type": "g"}},
+		{keys: map[string]string{"up": "k", "down": "j", "select": "l", "quit": "h"}, panel: "list", want: map[string]string{"up": "k", "down": "j", "select": "l"}},
+	}
+	for _, tt := range tests {
+		setKeys(nil)
+		err := setKeys(tt.keys)
+		if (err != nil) != tt.err {
+			t.Errorf("setKeys(%v): error %v", tt.keys, err)
+			continue
+		}
+		for action, key := range tt.want {
+			if got := boundKey(tt.panel, action); got != key {
+				t.Errorf("setKeys(%v): %s.%s is bound to %q, want %q", tt.keys, tt.panel, action, got, key)
+			}
+		}
+	}
+}
+
+func TestSetKeysKeepsBindingsOnError(t *testing.T) {
+	defer setKeys(nil)
+	if err := setKeys(map[string]string{"images.remove": "D"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := setKeys(map[string]string{"table.reverse": "<left>"}); err == nil {
+		t.Fatal("conflicting keys are accepted")
+	}
+	if got := boundKey("images", "remove"); got != "D" {
+		t.Errorf("images.remove is bound to %q, want D", got)
+	}
+}
+
+func TestTranslateKey(t *testing.T) {
+	defer setKeys(nil)
+	tests := []struct {
+		keys   map[string]string
+		key    string
+		panels []string
+		want   string
+	}{
+		{key: "d", panels: []string{"images", "list"}, want: "d"},
+		// keys which are not bound are passed on
+		{key: "q", panels: []string{"images", "list"}, want: "q"},
+		{key: "x", panels: []string{"images", "list"}, want: "x"},
+		// the panel falls through to the lists
+		{key: "<up>", panels: []string{"images", "list"}, want: "<up>"},
+		{keys: map[string]string{"images.remove": "D"}, key: "D", panels: []string{"images", "list"}, want: "d"},
+		{keys: map[string]string{"images.remove": "D"}, key: "d", panels: []string{"images", "list"}, want: ""},
+		{keys: map[string]string{"images.remove": "D"}, key: "d", panels: []string{"volumes", "list"}, want: "d"},
+		{keys: map[string]string{"up": "k"}, key: "k", panels: []string{"images", "list"}, want: "<up>"},
+		{keys: map[string]string{"up": "k"}, key: "<up>", panels: []string{"images", "list"}, want: ""},
+		// the first panel which binds the key wins
+		{key: "c", panels: []string{"events", "list", ""}, want: "c"},
+		{keys: map[string]string{"events.filter-container": "C"}, key: "c", panels: []string{"events", "list", ""}, want: ""},
+		{keys: map[string]string{"compare": "x"}, key: "x", panels: []string{"list", ""}, want: "c"},
+		{keys: map[string]string{"compare": "x"}, key: "c", panels: []string{"list", ""}, want: ""},
+	}
+	for _, tt := range tests {
+		if err := setKeys(tt.keys); err != nil {
+			t.Fatal(err)
+		}
+		if got := translateKey(tt.key, tt.panels...); got != tt.want {
+			t.Errorf("translateKey(%q, %v) with %v = %q, want %q", tt.key, tt.panels, tt.keys, got, tt.want)
+		}
+	}
+}
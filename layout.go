@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+
+	ui "github.com/gizak/termui"
+)
+
+// layoutConfig is a named layout of the main panel. The title is always
+// shown in the first row.
+type layoutConfig struct {
+	Name string            `json:"name"`
+	Rows []layoutRowConfig `json:"rows"`
+}
+
+type layoutRowConfig struct {
+	Cols []layoutColConfig `json:"cols"`
+}
+
+// layoutColConfig is a column of a row. The spans and offsets of the
+// columns of a row must not sum up to more than 12. The widgets of a
+// column are stacked.
+type layoutColConfig struct {
+	Span    int      `json:"span"`
+	Offset  int      `json:"offset"`
+	Widgets []string `json:"widgets"`
+}
+
+var defaultLayout = layoutConfig{
+	Name: "default",
+	Rows: []layoutRowConfig{
+		{Cols: []layoutColConfig{
+			{Span: 3, Widgets: []string{"containers"}},
+			{Span: 6, Widgets: []string{"memory"}},
+			{Span: 3, Widgets: []string{"memory-values"}}}},
+		{Cols: []layoutColConfig{
+			{Span: 6, Widgets: []string{"cpu"}},
+			{Span: 3, Widgets: []string{"rx"}},
+			{Span: 3, Widgets: []string{"tx"}}}},
+	},
+}
+
+// the widgets which can be placed in a layout
+var layoutWidgets = []string{"containers", "cpu", "memory", "memory-values", "rx", "tx", "blkio-read", "blkio-write"}
+
+// checkLayouts checks that the layouts only contain known widgets and
+// that the columns fit into their rows.
+func checkLayouts(layouts []layoutConfig) error {
+	known := make(map[string]bool)
+	for _, w := range layoutWidgets {
+		known[w] = true
+	}
+	for _, l := range layouts {
+		for i, r := range l.Rows {
+			width := 0
+			for _, c := range r.Cols {
+				if c.Span < 1 || c.Span > 12 {
+					return fmt.Errorf("layout %s: row %d: span %d is not in 1..12", l.Name, i+1, c.Span)
+				}
+				if c.Offset < 0 {
+					return fmt.Errorf("layout %s: row %d: negative offset %d", l.Name, i+1, c.Offset)
+				}
+				width += c.Span + c.Offset
+				for _, name := range c.Widgets {
+					if !known[name] {
+						return fmt.Errorf("layout %s: unknown widget %s", l.Name, name)
+					}
+				}
+			}
+			if width > 12 {
+				return fmt.Errorf("layout %s: row %d: the columns are %d wide, more than 12", l.Name, i+1, width)
+			}
+		}
+	}
+	return nil
+}
+
+// layoutPanel creates the grid of the layout with the given widgets.
+func layoutPanel(title ui.GridBufferer, layout layoutConfig, widgets map[string]ui.GridBufferer) (*ui.Grid, error) {
+	p := &ui.Grid{}
+
+	p.AddRows(
+		ui.NewRow(
+			ui.NewCol(12, 0, title)))
+	for _, r := range layout.Rows {
+		var cols []*ui.Row
+		for _, c := range r.Cols {
+			var ws []ui.GridBufferer
+			for _, name := range c.Widgets {
+				w, ok := widgets[name]
+				if !ok {
+					return nil, fmt.Errorf("layout %s: unknown widget %s", layout.Name, name)
+				}
+				ws = append(ws, w)
+			}
+			cols = append(cols, ui.NewCol(c.Span, c.Offset, ws...))
+		}
+		p.AddRows(ui.NewRow(cols...))
+	}
+
+	return p, nil
+}
+
+// layoutPanels creates the grids of all configured layouts. Without a
+// configured layout, the default layout is used.
+func layoutPanels(title ui.GridBufferer, layouts []layoutConfig, widgets map[string]ui.GridBufferer) ([]*ui.Grid, []string, error) {
+	if len(layouts) == 0 {
+		layouts = []layoutConfig{defaultLayout}
+	}
+	var (
+		grids []*ui.Grid
+		names []string
+	)
+	for _, l := range layouts {
+		g, err := layoutPanel(title, l, widgets)
+		if err != nil {
+			return nil, nil, err
+		}
+		grids = append(grids, g)
+		names = append(names, l.Name)
+	}
+	return grids, names, nil
+}
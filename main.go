@@ -3,11 +3,14 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	ui "github.com/gizak/termui"
+	"github.com/nsf/termbox-go"
 	"github.com/samalba/dockerclient"
 )
 
@@ -27,15 +30,23 @@ var (
 	statsData             = make(map[string][]*dockerclient.Stats)
 	lock                  sync.Mutex
 	uiStack               []*ui.Grid
+	panelDrawers          = make(map[*ui.Grid][]dockerDrawer)
+	panelKeys             = make(map[*ui.Grid]keyHandler)
+	panelNames            = make(map[*ui.Grid]string)
 )
 
+const containerListLabel = "Containers (#num or enter for details)"
+
 type dockerDrawer func(*dockerclient.DockerClient)
 
-type networkDiffer func(cur *dockerclient.NetworkStats, prev *dockerclient.NetworkStats) int
+// keyHandler handles the keys of a panel. It returns false if the key was
+// not consumed.
+type keyHandler func(dc *dockerclient.DockerClient, key string) bool
+
+type networkDiffer func(cur *dockerclient.NetworkStats, prev *dockerclient.NetworkStats, secs float64) int
 
 func dockerStats(id string, stats *dockerclient.Stats, errs chan error, data ...interface{}) {
 	lock.Lock()
-	defer lock.Unlock()
 	dat, _ := statsData[id]
 	// if we have more stats than visible columns in console, scroll.
 	if len(dat) > (ui.Body.Width - 2) {
@@ -43,26 +54,32 @@ func dockerStats(id string, stats *dockerclient.Stats, errs chan error, data ...
 	}
 	if len(dat) > 0 && dat[len(dat)-1].Read == stats.Read {
 		// same stat twice, ignore
+		lock.Unlock()
 		return
 	}
 	dat = append(dat, stats)
 	statsData[id] = dat
+	s, ok := lastSample(dat)
+	name := containerNameByID(id)
+	lock.Unlock()
+	// the file is written without holding the lock
+	if ok && *csvDir != "" && !*csvWide {
+		writeContainerCSV(id, name, s)
+	}
 }
 
 func containerList() (dockerDrawer, ui.GridBufferer) {
 	list := ui.NewList()
-	list.ItemFgColor = ui.ColorYellow
-	list.BorderLabel = "Containers (#num for details)"
+	list.ItemFgColor = currentTheme.Items
+	list.BorderLabel = containerListLabel
 	return func(dc *dockerclient.DockerClient) {
 		containers, err := dc.ListContainers(false, false, "")
 		if err != nil {
 			containerDetailsID = ""
 			dc.StopAllMonitorStats()
 		} else {
-			var conts []string
 			newstats := make(map[string][]*dockerclient.Stats)
 			for i, c := range containers {
-				conts = append(conts, genContainerListName(i, c, 30))
 				if i == containerDetailsIndex {
 					containerDetailsID = c.Id
 				}
@@ -72,22 +89,34 @@ func containerList() (dockerDrawer, ui.GridBufferer) {
 				} else {
 					errs := make(chan error)
 					dc.StartMonitorStats(c.Id, dockerStats, errs, &c)
+					go inspectCrashes(dc, c.Id)
 				}
 			}
+			closeStaleCSV(newstats)
 			lock.Lock()
 			defer lock.Unlock()
 			statsData = newstats
 			allcontainers = containers
+			rememberContainerNames()
+			pruneMarkedContainers()
 			if len(allcontainers) == 0 {
 				dc.StopAllMonitorStats()
 				containerDetailsID = ""
 			}
-			list.Items = conts
-			list.Height = len(conts) + 2
+			updateContainerList(list)
 		}
 	}, list
 }
 
+func containerName(c dockerclient.Container) string {
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// highlight marks the given list item as selected.
+func highlight(s string) string {
+	return fmt.Sprintf("[%s](%s)", s, currentTheme.Highlight)
+}
+
 func genContainerListName(idx int, c dockerclient.Container, maxlen int) string {
 	s := fmt.Sprintf("[%d] %s:%s", idx, c.Names[0], c.Id)
 	if len(s) > maxlen {
@@ -96,44 +125,60 @@ func genContainerListName(idx int, c dockerclient.Container, maxlen int) string
 	return s
 }
 
-func containerDetails() (dockerDrawer, ui.GridBufferer) {
-	list := ui.NewList()
-	list.ItemFgColor = ui.ColorYellow
-	list.BorderLabel = "Details"
-	return func(dc *dockerclient.DockerClient) {
+func containerDetails() (dockerDrawer, keyHandler, ui.GridBufferer, *dashboard) {
+	var lineSections []string
+	list := newSelectList("Details")
+	dash := newDashboard()
+	drawer := func(dc *dockerclient.DockerClient) {
 		if containerDetailsID == "" {
 			list.Height = 2
+			dash.update(nil, nil)
 			return
 		}
 		ci, err := dc.InspectContainer(containerDetailsID)
 		if err != nil {
 			// don't log !
 		} else {
+			lock.Lock()
+			stats := statsData[containerDetailsID]
+			lock.Unlock()
+			dash.update(ci, stats)
+			cix, err := inspectContainerExt(dc, containerDetailsID)
+			if err != nil {
+				cix = nil
+			}
 			var lines []string
-			lines = append(lines, fmt.Sprintf("Name: %s", ci.Name))
-			lines = append(lines, fmt.Sprintf("Image: %s", ci.Image))
-			lines = append(lines, fmt.Sprintf("Path: %s", ci.Path))
-			lines = append(lines, fmt.Sprintf("Args: %s", ci.Args))
-			lines = append(lines, fmt.Sprintf("IP: %s", ci.NetworkSettings.IPAddress))
-			lines = append(lines, fmt.Sprintf("Ports: %s", genPortMappings(ci)))
-			for vi, v := range genVolumes(ci) {
-				if vi == 0 {
-					lines = append(lines, fmt.Sprintf("Volumes: %s", v))
-				} else {
-					lines = append(lines, fmt.Sprintf("         %s", v))
-				}
+			lines, lineSections = genDetailLines(genDetailSections(ci, cix))
+			list.label = fmt.Sprintf("Details: %s (%s to collapse, %s to reveal secrets)", ci.Name, quoteKey(boundKey("list", "select")), quoteKey(boundKey("details", "reveal")))
+			list.setLines(lines)
+		}
+	}
+	keys := func(dc *dockerclient.DockerClient, key string) bool {
+		if list.handleKey(key) {
+			return true
+		}
+		if key == "r" {
+			revealSecrets()
+			drawer(dc)
+			return true
+		}
+		if key != "<enter>" || len(lineSections) == 0 {
+			return false
+		}
+		sec := lineSections[list.cursor]
+		collapsedSections[sec] = !collapsedSections[sec]
+		drawer(dc)
+		// keep the cursor on the header of the section
+		for i, s := range lineSections {
+			if s == sec {
+				list.cursor = i
+				list.update()
+				break
 			}
-			lines = append(lines, fmt.Sprintf("Hostname: %s", ci.Config.Hostname))
-			lines = append(lines, fmt.Sprintf("Memory: %d", ci.Config.Memory))
-			lines = append(lines, fmt.Sprintf("Swap: %d", ci.Config.MemorySwap))
-			lines = append(lines, fmt.Sprintf("Cpu-Shares: %d", ci.Config.CpuShares))
-			lines = append(lines, fmt.Sprintf("Cpu-Set: %s", ci.Config.Cpuset))
-			lines = append(lines, fmt.Sprintf("Env: %s", ci.Config.Env))
-			list.Items = lines
-			list.Height = len(lines) + 2
-			list.BorderLabel = fmt.Sprintf("Details: %s", ci.Name)
 		}
-	}, list
+		return true
+	}
+	return drawer, keys, list, dash
 }
 
 func genPortMappings(di *dockerclient.ContainerInfo) string {
@@ -165,106 +210,186 @@ func genVolumes(di *dockerclient.ContainerInfo) []string {
 	return res
 }
 
-func containerCPU() (dockerDrawer, ui.GridBufferer) {
+func containerCPU() (dockerDrawer, *historyPanel) {
 	cpus := ui.NewSparklines()
 	cpus.BorderLabel = "CPU"
+	hist := newHistoryPanel(cpus)
 	return func(dc *dockerclient.DockerClient) {
 		cpus.Lines = []ui.Sparkline{}
 		cpus.Height = 2
-		for _, c := range allcontainers {
-			dat, _ := statsData[c.Id]
+		hist.reset()
+		groups := panelGroups()
+		from, to := hist.window(groups)
+		for _, g := range groups[from:to] {
+			data := genGroupSeries(g, genCPUSystemUsage)
 			lastVal := 0
-			if len(dat) > 1 {
-				lastVal = cpuPercent(dat, len(dat)-1)
+			if len(data) > 0 {
+				lastVal = data[len(data)-1]
+			}
+			label := g.label()
+			if !groupView {
+				c := allcontainers[g.Members[0]]
+				label = fmt.Sprintf("%s:%s", c.Names, c.Id)
 			}
 			l := ui.NewSparkline()
-			l.Title = fmt.Sprintf("[%d %%] %s:%s ", lastVal, c.Names, c.Id)
-			l.LineColor = ui.ColorYellow
-			l.Data = genCPUSystemUsage(dat)
+			l.Title = fmt.Sprintf("[%d %%] %s ", lastVal, label)
+			if clr, ok := groupHealthColor(g); ok {
+				l.TitleColor = clr
+			}
+			l.LineColor = thresholds.CPU.color(float64(lastVal), currentTheme.CPU)
+			l.Data = data
 			l.Height = 2
 			cpus.Lines = append(cpus.Lines, l)
 			cpus.Height = cpus.Height + 3
+			hist.groups = append(hist.groups, g)
+			if hist.lines {
+				hist.addChart(l.Title, data, genGroupTimes(g), l.LineColor, formatPercent)
+			}
+			if hist.compact() {
+				hist.addLine(l.Title, data, l.LineColor)
+			}
 		}
+		cpus.BorderLabel = "CPU" + hist.label()
+		hist.list.BorderLabel = cpus.BorderLabel
+
+	}, hist
+}
+
+func containerNetworkBytes(lbl string, differ networkDiffer, color ui.Attribute) (dockerDrawer, *historyPanel) {
+	return containerBytes(lbl, func(stats []*dockerclient.Stats) []int {
+		return genNetwork(stats, differ)
+	}, color, thresholds.Network)
+}
 
-	}, cpus
+func containerBlkioBytes(lbl string, op string, color ui.Attribute) (dockerDrawer, *historyPanel) {
+	return containerBytes(lbl, func(stats []*dockerclient.Stats) []int {
+		return genBlkio(stats, op)
+	}, color, threshold{})
 }
 
-func containerNetworkBytes(lbl string, differ networkDiffer, color ui.Attribute) (dockerDrawer, ui.GridBufferer) {
+func containerBytes(lbl string, gen func([]*dockerclient.Stats) []int, color ui.Attribute, limit threshold) (dockerDrawer, *historyPanel) {
 	netw := ui.NewSparklines()
 	netw.BorderLabel = lbl
+	hist := newHistoryPanel(netw)
 	return func(dc *dockerclient.DockerClient) {
 		netw.Lines = []ui.Sparkline{}
 		netw.Height = 2
-		for idx, c := range allcontainers {
-			dat, _ := statsData[c.Id]
-			if len(dat) > 1 {
+		hist.reset()
+		groups := panelGroups()
+		from, to := hist.window(groups)
+		for _, g := range groups[from:to] {
+			data := genGroupSeries(g, gen)
+			if len(data) > 0 {
 				l := ui.NewSparkline()
-				l.LineColor = color
-				l.Data = genNetwork(dat, differ)
-				tx := 0
-				if len(l.Data) > 0 {
-					tx = l.Data[len(l.Data)-1]
+				l.Data = data
+				tx := l.Data[len(l.Data)-1]
+				l.LineColor = limit.color(float64(tx), color)
+				label := g.label()
+				if !groupView {
+					label = genContainerListName(g.Members[0], allcontainers[g.Members[0]], 20)
+				}
+				l.Title = fmt.Sprintf("[%5s] %s", memAsString(uint64(tx)), label)
+				if clr, ok := groupHealthColor(g); ok {
+					l.TitleColor = clr
 				}
-				l.Title = fmt.Sprintf("[%5s] %s", memAsString(uint64(tx)), genContainerListName(idx, c, 20))
 				l.Height = 2
 				netw.Lines = append(netw.Lines, l)
 				netw.Height = netw.Height + 3
+				hist.groups = append(hist.groups, g)
+				if hist.lines {
+					hist.addChart(fmt.Sprintf("%s %s", lbl, l.Title), data, genGroupTimes(g), l.LineColor, formatBytes)
+				}
+				if hist.compact() {
+					hist.addLine(l.Title, data, l.LineColor)
+				}
 			}
 		}
+		netw.BorderLabel = lbl + hist.label()
+		hist.list.BorderLabel = netw.BorderLabel
 
-	}, netw
+	}, hist
 }
 
-func containerPercentMemory() (dockerDrawer, ui.GridBufferer) {
-	mem := ui.NewBarChart()
+func containerPercentMemory() (dockerDrawer, *historyPanel) {
+	mem := newColorBarChart()
 	mem.BorderLabel = "Memory % usage "
 	mem.Height = 13
 	mem.BarWidth = 5
 	mem.SetMax(100)
-	mem.BarColor = ui.ColorRed
+	mem.BarColor = currentTheme.Memory
+	hist := newHistoryPanel(mem)
 	return func(dc *dockerclient.DockerClient) {
 		var labels []string
 		var used []int
-		for i, c := range allcontainers {
-			labels = append(labels, fmt.Sprintf("[%2d]", i))
-			dat, _ := statsData[c.Id]
-			if len(dat) > 1 {
-				last := dat[len(dat)-1]
-				memused := last.MemoryStats.Usage
-				memlim := last.MemoryStats.Limit
-				memusedP := int(100 * memused / memlim)
-				used = append(used, memusedP)
+		var colors, labelColors []ui.Attribute
+		hist.reset()
+		groups := panelGroups()
+		from, to := hist.window(groups)
+		for _, g := range groups[from:to] {
+			if groupView {
+				labels = append(labels, g.Name)
+			} else {
+				labels = append(labels, fmt.Sprintf("[%2d]", g.Members[0]))
+			}
+			hclr, _ := groupHealthColor(g)
+			labelColors = append(labelColors, hclr)
+			s := groupSample(g)
+			clr := thresholds.Memory.color(float64(s.memPercent()), mem.BarColor)
+			if s.MemLimit > 0 {
+				used = append(used, s.memPercent())
+				colors = append(colors, clr)
+			}
+			if s.MemLimit > 0 || hist.lines || hist.compact() {
+				hist.groups = append(hist.groups, g)
+			}
+			label := g.label()
+			if !groupView {
+				label = genContainerListName(g.Members[0], allcontainers[g.Members[0]], 30)
+			}
+			if hist.lines {
+				hist.addChart(fmt.Sprintf("Memory %s", label), genGroupSeries(g, genMemory), genGroupTimes(g), clr, formatBytes)
+			}
+			if hist.compact() {
+				hist.addLine(fmt.Sprintf("[%3d %%] %s", s.memPercent(), label), genGroupSeries(g, genMemory), clr)
 			}
 		}
+		mem.BorderLabel = "Memory % usage" + hist.label()
+		hist.list.BorderLabel = mem.BorderLabel
 		mem.DataLabels = labels
 		mem.Data = used
+		mem.BarColors = colors
+		mem.LabelColors = labelColors
 		mx := findMaxInt(used)
 		if mx < 30 {
 			mem.SetMax(mx * 3)
 		} else {
 			mem.SetMax(100)
 		}
-	}, mem
+	}, hist
 }
 
-func containerValueMemory() (dockerDrawer, ui.GridBufferer) {
-	list := ui.NewList()
-	list.ItemFgColor = ui.ColorYellow
+func containerValueMemory() (dockerDrawer, *pagedList) {
+	list := &pagedList{List: ui.NewList()}
+	list.ItemFgColor = currentTheme.Items
 	list.BorderLabel = "Container Memory"
 
 	return func(dc *dockerclient.DockerClient) {
 		var labels []string
-		for i, c := range allcontainers {
-			dat, _ := statsData[c.Id]
-			var memused uint64
-			if len(dat) > 1 {
-				last := dat[len(dat)-1]
-				memused = last.MemoryStats.Usage
+		groups := panelGroups()
+		from, to := list.window(len(groups), maxPanelHeight(list)-2, selectedGroup(groups))
+		list.groups = groups[from:to]
+		for _, g := range list.groups {
+			memused := groupSample(g).MemUsage
+			if groupView {
+				labels = append(labels, fmt.Sprintf("%s: %s", g.label(), memAsString(memused)))
+			} else {
+				c := allcontainers[g.Members[0]]
+				labels = append(labels, colorByHealth(c, fmt.Sprintf("[%2d]: %s", g.Members[0], memAsString(memused))))
 			}
-			labels = append(labels, fmt.Sprintf("[%2d]: %s", i, memAsString(memused)))
 		}
 		list.Items = labels
 		list.Height = len(labels) + 2
+		list.BorderLabel = "Container Memory" + list.label()
 	}, list
 }
 
@@ -284,20 +409,49 @@ func genNetwork(stats []*dockerclient.Stats, differ networkDiffer) []int {
 		if i > 0 {
 			stat1 := stats[i]
 			stat2 := stats[i-1]
-			res = append(res, differ(&stat1.NetworkStats, &stat2.NetworkStats))
+			secs := stat1.Read.Sub(stat2.Read).Seconds()
+			res = append(res, differ(&stat1.NetworkStats, &stat2.NetworkStats, secs))
 		}
 	}
 	return res
 }
 
-func rxDiffer(cur *dockerclient.NetworkStats, prev *dockerclient.NetworkStats) int {
-	return int(cur.RxBytes - prev.RxBytes)
+func genMemory(stats []*dockerclient.Stats) []int {
+	var res []int
+	for i := range stats {
+		if i > 0 {
+			res = append(res, int(stats[i].MemoryStats.Usage))
+		}
+	}
+	return res
+}
+
+func genBlkio(stats []*dockerclient.Stats, op string) []int {
+	var res []int
+	for i := range stats {
+		if i > 0 {
+			cur := stats[i]
+			prev := stats[i-1]
+			secs := cur.Read.Sub(prev.Read).Seconds()
+			res = append(res, int(perSecond(blkioBytes(&cur.BlkioStats, op), blkioBytes(&prev.BlkioStats, op), secs)))
+		}
+	}
+	return res
+}
+
+func rxDiffer(cur *dockerclient.NetworkStats, prev *dockerclient.NetworkStats, secs float64) int {
+	return int(perSecond(cur.RxBytes, prev.RxBytes, secs))
 }
-func txDiffer(cur *dockerclient.NetworkStats, prev *dockerclient.NetworkStats) int {
-	return int(cur.TxBytes - prev.TxBytes)
+func txDiffer(cur *dockerclient.NetworkStats, prev *dockerclient.NetworkStats, secs float64) int {
+	return int(perSecond(cur.TxBytes, prev.TxBytes, secs))
 }
 
 func cpuPercent(stats []*dockerclient.Stats, idx int) int {
+	return int(cpuPercentFloat(stats, idx))
+}
+
+// cpuPercentFloat returns the unrounded cpu usage of the stat at idx.
+func cpuPercentFloat(stats []*dockerclient.Stats, idx int) float64 {
 	var (
 		p        = 0.0
 		mystat   = stats[idx]
@@ -309,7 +463,7 @@ func cpuPercent(stats []*dockerclient.Stats, idx int) int {
 	if sysdelta > 0.0 && cpudelta > 0.0 {
 		p = (cpudelta / sysdelta) * float64(len(mystat.CpuStats.CpuUsage.PercpuUsage)) * 100.0
 	}
-	return int(p)
+	return p
 }
 
 func memAsString(val uint64) string {
@@ -345,14 +499,38 @@ func findMaxInt(vals []int) int {
 	return max
 }
 
+// exitUsage prints the error in the flags or the configuration and exits
+// before the terminal is initialized.
+func exitUsage(err error) {
+	fmt.Fprintf(os.Stderr, "dockmon: %s\n", err)
+	flag.Usage()
+	os.Exit(2)
+}
+
 func main() {
 	flag.Parse()
 
-	err := ui.Init()
+	var err error
+	groupKey, err = parseGroupBy(*groupBy)
 	if err != nil {
-		panic(err)
+		exitUsage(err)
+	}
+	groupView = *groupBy != ""
+
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		exitUsage(fmt.Errorf("%s: %s", *configFile, err))
+	}
+	if err := checkLayouts(cfg.Layouts); err != nil {
+		exitUsage(fmt.Errorf("%s: %s", *configFile, err))
+	}
+	if err := setTheme(cfg.Theme); err != nil {
+		exitUsage(fmt.Errorf("%s: %s", *configFile, err))
+	}
+	thresholds = cfg.Thresholds
+	if err := setKeys(cfg.Keys); err != nil {
+		exitUsage(fmt.Errorf("%s: %s", *configFile, err))
 	}
-	defer ui.Close()
 
 	// Init the client
 	docker, err := dockerclient.NewDockerClient(*dockersocket, nil)
@@ -360,25 +538,143 @@ func main() {
 		panic(err)
 	}
 
+	if *noStream {
+		if err := printStats(docker, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *csvDir != "" {
+		if err := os.MkdirAll(*csvDir, 0755); err != nil {
+			exitUsage(err)
+		}
+	}
+
+	err = ui.Init()
+	if err != nil {
+		panic(err)
+	}
+	defer ui.Close()
+	if !*noMouse {
+		termbox.SetInputMode(termbox.InputEsc | termbox.InputMouse)
+	}
+
+	if info, err := docker.Info(); err == nil && info.MemTotal > 0 {
+		hostMemory = uint64(info.MemTotal)
+	}
+	startEventMonitor(docker)
+	if *otlpEndpoint != "" {
+		startOTLPExport(docker)
+	}
+
 	var drawers []dockerDrawer
 	containerlist, uiCntList := containerList()
-	containerDetails, uiCntDets := containerDetails()
+	containerDetails, detailsKeys, uiCntDets, uiDashboard := containerDetails()
 	cpuList, uiCpus := containerCPU()
 	memUsg, uiMem := containerPercentMemory()
 	memVal, uiMemVal := containerValueMemory()
-	rxVal, uiRx := containerNetworkBytes("Rx Bytes", rxDiffer, ui.ColorGreen)
-	txVal, uiTx := containerNetworkBytes("Tx Bytes", txDiffer, ui.ColorBlue)
-
-	drawers = append(drawers, containerlist, containerDetails, cpuList, memUsg, memVal, rxVal, txVal)
-
-	title := ui.NewPar(fmt.Sprintf("dockmon %s ('q' to quit panel)", version))
+	rxVal, uiRx := containerNetworkBytes("Rx Bytes", rxDiffer, currentTheme.Rx)
+	txVal, uiTx := containerNetworkBytes("Tx Bytes", txDiffer, currentTheme.Tx)
+	blkReadVal, uiBlkRead := containerBlkioBytes("Blkio Read Bytes", "Read", currentTheme.BlkioRead)
+	blkWriteVal, uiBlkWrite := containerBlkioBytes("Blkio Write Bytes", "Write", currentTheme.BlkioWrite)
+
+	imageList, imageKeys, uiImages := imageList()
+	volumeList, volumeKeys, uiVolumes := volumeList()
+	networkList, networkKeys, uiNetworks := networkList()
+	changesList, changesKeys, uiChanges := containerChanges()
+	eventList, eventKeys, uiEvents := eventTimeline()
+	compareCharts, uiCompare := containerCompare()
+
+	drawers = append(drawers, containerlist, containerDetails, cpuList, memUsg, memVal, rxVal, txVal, blkReadVal, blkWriteVal)
+
+	titleText := fmt.Sprintf("dockmon %s (%s for help, %s to quit panel)", version, quoteKey(boundKey("", "help")), quoteKey(boundKey("", "quit")))
+	title := ui.NewPar(titleText)
 	title.Height = 3
 	title.Border = true
 
-	mainGrid := mainPanel(title, uiCntList, uiCpus, uiMem, uiMemVal, uiRx, uiTx)
-	detailsGrid := detailsPanel(title, uiCntDets)
+	mainGrids, layoutNames, err := layoutPanels(title, cfg.Layouts, map[string]ui.GridBufferer{
+		"containers":    uiCntList,
+		"cpu":           uiCpus,
+		"memory":        uiMem,
+		"memory-values": uiMemVal,
+		"rx":            uiRx,
+		"tx":            uiTx,
+		"blkio-read":    uiBlkRead,
+		"blkio-write":   uiBlkWrite,
+	})
+	if err != nil {
+		panic(err)
+	}
+	layoutIndex := 0
+	detailsGrid := dashboardPanel(title, uiDashboard, uiCntDets)
+	panelKeys[detailsGrid] = detailsKeys
+	panelNames[detailsGrid] = "details"
+	imagesGrid := detailsPanel(title, uiImages)
+	panelDrawers[imagesGrid] = []dockerDrawer{imageList}
+	panelKeys[imagesGrid] = imageKeys
+	panelNames[imagesGrid] = "images"
+	volumesGrid := detailsPanel(title, uiVolumes)
+	panelDrawers[volumesGrid] = []dockerDrawer{volumeList}
+	panelKeys[volumesGrid] = volumeKeys
+	panelNames[volumesGrid] = "volumes"
+	networksGrid := detailsPanel(title, uiNetworks)
+	panelDrawers[networksGrid] = []dockerDrawer{networkList}
+	panelKeys[networksGrid] = networkKeys
+	panelNames[networksGrid] = "networks"
+	changesGrid := detailsPanel(title, uiChanges)
+	panelKeys[changesGrid] = changesKeys
+	panelNames[changesGrid] = "changes"
+	compareGrid := comparePanel(title, uiCompare)
+	panelDrawers[compareGrid] = []dockerDrawer{compareCharts}
+	panelNames[compareGrid] = "compare"
+	eventsGrid := detailsPanel(title, uiEvents)
+	panelDrawers[eventsGrid] = []dockerDrawer{eventList}
+	panelKeys[eventsGrid] = eventKeys
+	panelNames[eventsGrid] = "events"
+	showContainer := func(idx int) {
+		containerDetailsIndex = idx
+		pushPanel(detailsGrid)
+		containerDetails(docker)
+	}
+	tableList, tableKeys, uiTable := containerTable(showContainer)
+	tableGrid := detailsPanel(title, uiTable)
+	panelDrawers[tableGrid] = []dockerDrawer{tableList}
+	panelKeys[tableGrid] = tableKeys
+	panelNames[tableGrid] = "table"
+	helpList := ui.NewList()
+	helpList.ItemFgColor = currentTheme.Items
+	helpGrid := detailsPanel(title, helpList)
+
+	showGroup := func(g *containerGroup, ok bool) {
+		if ok && len(g.Members) == 1 {
+			showContainer(g.Members[0])
+		}
+	}
+	for _, p := range []*historyPanel{uiCpus, uiMem, uiRx, uiTx, uiBlkRead, uiBlkWrite} {
+		p := p
+		clickHandlers[p] = func(x, y int) {
+			showGroup(p.groupAt(x, y))
+		}
+	}
+	clickHandlers[uiMemVal] = func(x, y int) {
+		showGroup(uiMemVal.groupAt(y))
+	}
+	clickHandlers[uiCntList] = func(x, y int) {
+		if !clickListRow(uiCntList.(*ui.List), y) {
+			return
+		}
+		if idx, ok := selectListRow(uiCntList.(*ui.List)); ok {
+			showContainer(idx)
+		}
+	}
+	var (
+		maximizedGrid *ui.Grid
+		exportFailed  bool
+	)
 
-	ui.Body = pushPanel(mainGrid)
+	ui.Body = pushPanel(mainGrids[layoutIndex])
 	ui.Body.Width = ui.TermWidth()
 	ui.Body.Align()
 
@@ -386,22 +682,219 @@ func main() {
 
 	ui.Handle("/sys/kbd/", func(evt ui.Event) {
 		ch := evt.Data.(ui.EvtKbd)
-		key := ch.KeyStr[0]
-		if key == 'q' {
-			_, err := popPanel()
-			if err != nil {
+		panel := panelNames[ui.Body]
+		if h, ok := panelKeys[ui.Body]; ok {
+			if k := translateKey(ch.KeyStr, panel, "list"); k != "" && h(docker, k) {
+				ui.Render(ui.Body)
+				return
+			}
+		}
+		keyStr := translateKey(ch.KeyStr, "list", "")
+		if keyStr == "" {
+			return
+		}
+		// the cursor keys of the lists move the cursor of the container
+		// list only in the main panel
+		if ui.Body != mainGrids[layoutIndex] && ui.Body != maximizedGrid {
+			switch keyStr {
+			case "<up>", "<down>", "<previous>", "<next>", "<enter>":
+				return
+			}
+		}
+		switch keyStr {
+		case "?":
+			if ui.Body != helpGrid {
+				helpList.BorderLabel = fmt.Sprintf("Keys (%s to close)", quoteKey(boundKey("", "quit")))
+				helpList.Items = genHelp(panel)
+				helpList.Height = len(helpList.Items) + 2
+				pushPanel(helpGrid)
+				ui.Render(ui.Body)
+			}
+			return
+		case "<tab>":
+			if len(uiStack) == 1 {
+				layoutIndex = (layoutIndex + 1) % len(mainGrids)
+				uiStack = uiStack[:0]
+				pushPanel(mainGrids[layoutIndex])
+				title.Text = fmt.Sprintf("%s - layout %s", titleText, layoutNames[layoutIndex])
+				ui.Render(ui.Body)
+			}
+			return
+		case "<up>":
+			moveListCursor(uiCntList.(*ui.List), -1)
+			ui.Render(ui.Body)
+			return
+		case "<previous>":
+			moveListCursor(uiCntList.(*ui.List), -(maxPanelHeight(uiCntList) - 2))
+			ui.Render(ui.Body)
+			return
+		case "<next>":
+			moveListCursor(uiCntList.(*ui.List), maxPanelHeight(uiCntList)-2)
+			ui.Render(ui.Body)
+			return
+		case "<down>":
+			moveListCursor(uiCntList.(*ui.List), 1)
+			ui.Render(ui.Body)
+			return
+		case "<enter>":
+			if idx, ok := selectListRow(uiCntList.(*ui.List)); ok {
+				containerDetailsIndex = idx
+				pushPanel(detailsGrid)
+			}
+			ui.Render(ui.Body)
+			return
+		}
+		switch keyStr {
+		case "q":
+			if _, err := popPanel(); err != nil {
 				ui.StopLoop()
 			}
+		case "i":
+			if ui.Body != imagesGrid {
+				pushPanel(imagesGrid)
+				imageList(docker)
+			}
+		case "v":
+			if ui.Body != volumesGrid {
+				pushPanel(volumesGrid)
+				volumeList(docker)
+			}
+		case "n":
+			if ui.Body != networksGrid {
+				pushPanel(networksGrid)
+				networkList(docker)
+			}
+		case "f":
+			if ui.Body != changesGrid {
+				pushPanel(changesGrid)
+				changesList(docker)
+			}
+		case "m":
+			if ui.Body != compareGrid {
+				if err := markListRow(uiCntList.(*ui.List)); err != nil {
+					title.Text = fmt.Sprintf("%s - %s", titleText, err)
+				}
+			}
+		case "c":
+			if ui.Body != compareGrid {
+				if len(markedContainers) < 2 {
+					title.Text = fmt.Sprintf("%s - mark 2 to %d containers with %s to compare them", titleText, maxMarkedContainers, quoteKey(boundKey("", "mark")))
+				} else {
+					pushPanel(compareGrid)
+					compareCharts(docker)
+				}
+			}
+		case "t":
+			if ui.Body != tableGrid {
+				pushPanel(tableGrid)
+				tableList(docker)
+			}
+		case "e":
+			if ui.Body != eventsGrid {
+				pushPanel(eventsGrid)
+				eventList(docker)
+			}
+		case "C":
+			uiCpus.toggle()
+			cpuList(docker)
+		case "M":
+			uiMem.toggle()
+			memUsg(docker)
+		case "N":
+			uiRx.toggle()
+			uiTx.toggle()
+			rxVal(docker)
+			txVal(docker)
+		case "B":
+			uiBlkRead.toggle()
+			uiBlkWrite.toggle()
+			blkReadVal(docker)
+			blkWriteVal(docker)
+		case "g":
+			groupView = !groupView
+		case "z":
+			compactView = !compactView
+			for _, d := range drawers {
+				d(docker)
+			}
+			ui.Body.Align()
+		case "s":
+			fn, err := writeSnapshot(docker)
+			if err != nil {
+				title.Text = fmt.Sprintf("%s - snapshot failed: %s", titleText, err)
+			} else {
+				title.Text = fmt.Sprintf("%s - snapshot written to %s", titleText, fn)
+			}
+		case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if ui.Body != detailsGrid {
+				containerDetailsIndex = int(keyStr[0] - '0')
+				pushPanel(detailsGrid)
+			}
+		}
+	})
+	ui.Handle("/sys/mouse", func(e ui.Event) {
+		m := e.Data.(ui.EvtMouse)
+		w, top, ok := widgetAt(ui.Body, m.X, m.Y)
+		if !ok || w == title {
+			return
+		}
+		switch m.Press {
+		case "wheel-up", "wheel-down":
+			// the wheel scrolls the list of the panel, in the main panel
+			// the container list
+			key, delta := "<down>", 1
+			if m.Press == "wheel-up" {
+				key, delta = "<up>", -1
+			}
+			if h, ok := panelKeys[ui.Body]; ok {
+				h(docker, key)
+			} else if ui.Body == mainGrids[layoutIndex] || ui.Body == maximizedGrid {
+				moveListCursor(uiCntList.(*ui.List), delta)
+			}
+			ui.Render(ui.Body)
+			return
+		case "left", "":
+			// the button is empty if termui does not report it
+		default:
+			return
 		}
-		if key >= '0' && key <= '9' {
-			containerDetailsIndex = int(key - '0')
-			pushPanel(detailsGrid)
+		if m.Y == top {
+			// a click on the border label maximizes the widget
+			if ui.Body == maximizedGrid {
+				popPanel()
+			} else if len(uiStack) == 1 {
+				maximizedGrid = detailsPanel(title, w)
+				pushPanel(maximizedGrid)
+			}
+		} else if h, ok := clickHandlers[w]; ok {
+			h(m.X, m.Y)
 		}
+		ui.Render(ui.Body)
 	})
 	ui.Handle("/timer/1s", func(e ui.Event) {
 		for _, d := range drawers {
 			d(docker)
 		}
+		for _, d := range panelDrawers[ui.Body] {
+			d(docker)
+		}
+		if *csvDir != "" && *csvWide {
+			writeWideCSV(time.Now())
+		}
+		var failures []string
+		if err := csvError(); err != nil {
+			failures = append(failures, fmt.Sprintf("csv export failed: %s", err))
+		}
+		if err := otlpError(); err != nil {
+			failures = append(failures, fmt.Sprintf("otlp export failed: %s", err))
+		}
+		if len(failures) > 0 {
+			title.Text = fmt.Sprintf("%s - %s", titleText, strings.Join(failures, ", "))
+			exportFailed = true
+		} else if exportFailed {
+			title.Text = titleText
+			exportFailed = false
+		}
 		ui.Body.Align()
 		ui.Render(ui.Body)
 	})
@@ -432,24 +925,6 @@ func popPanel() (*ui.Grid, error) {
 	return last, nil
 }
 
-func mainPanel(title, cntList, cpus, mem, memval, rx, tx ui.GridBufferer) *ui.Grid {
-	p := &ui.Grid{}
-
-	p.AddRows(
-		ui.NewRow(
-			ui.NewCol(12, 0, title)),
-		ui.NewRow(
-			ui.NewCol(3, 0, cntList),
-			ui.NewCol(6, 0, mem),
-			ui.NewCol(3, 0, memval)),
-		ui.NewRow(
-			ui.NewCol(6, 0, cpus),
-			ui.NewCol(3, 0, rx),
-			ui.NewCol(3, 0, tx)))
-
-	return p
-}
-
 func detailsPanel(title, details ui.GridBufferer) *ui.Grid {
 	p := &ui.Grid{}
 
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+
+	ui "github.com/gizak/termui"
+)
+
+// the mouse mode of the terminal disables its text selection
+var noMouse = flag.Bool("no-mouse", false, "do not use the mouse, so text can be selected in the terminal")
+
+// clickHandler handles a click into a widget at the given screen position.
+type clickHandler func(x, y int)
+
+var clickHandlers = make(map[ui.GridBufferer]clickHandler)
+
+// widgetAt returns the widget of the grid at the given screen position and
+// the row of its upper border.
+func widgetAt(g *ui.Grid, x, y int) (ui.GridBufferer, int, bool) {
+	for _, r := range g.Rows {
+		if w, top, ok := rowWidgetAt(r, x, y); ok {
+			return w, top, true
+		}
+	}
+	return nil, 0, false
+}
+
+func rowWidgetAt(r *ui.Row, x, y int) (ui.GridBufferer, int, bool) {
+	if r.Widget != nil && x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Widget.GetHeight() {
+		return r.Widget, r.Y, true
+	}
+	for _, c := range r.Cols {
+		if w, top, ok := rowWidgetAt(c, x, y); ok {
+			return w, top, true
+		}
+	}
+	return nil, 0, false
+}
+
+// groupAt returns the group of the sparkline, chart or bar at the given
+// screen position.
+func (p *historyPanel) groupAt(x, y int) (*containerGroup, bool) {
+	var i int
+	switch w := p.spark.(type) {
+	case *ui.Sparklines:
+		// every sparkline has a title and a height of two rows
+		i = (y - p.y - 1) / 3
+	case *colorBarChart:
+		i = (x - w.InnerX()) / (w.BarWidth + w.BarGap)
+	}
+	switch {
+	case p.lines:
+		i = (y - p.y) / chartHeight
+	case p.compact():
+		i = y - p.y - 1
+	}
+	if i < 0 || i >= len(p.groups) {
+		return nil, false
+	}
+	return p.groups[i], true
+}
+
+// clickListRow moves the cursor of the container list to the row at the
+// given screen position.
+func clickListRow(l *ui.List, y int) bool {
+	i := y - l.InnerY() + listOffset
+	if i < listOffset || i >= len(listRows) {
+		return false
+	}
+	moveListCursor(l, i-listCursor)
+	return true
+}
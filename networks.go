@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	ui "github.com/gizak/termui"
+	"github.com/samalba/dockerclient"
+)
+
+// networksLabel returns the label of the network list with the keys to
+// connect and disconnect the container.
+func networksLabel(name string) string {
+	return fmt.Sprintf("Networks (%s/%s to connect/disconnect %s)", quoteKey(boundKey("networks", "connect")), quoteKey(boundKey("networks", "disconnect")), name)
+}
+
+// networkDetails caches the inspect data of the networks, the list of the
+// networks does not contain their members. The data of a network is
+// dropped when a container is connected or disconnected from dockmon; the
+// events of the networks do not reach the old client, so changes made by
+// others show up after networksTTL.
+var networkDetails = newTTLCache(networksTTL)
+
+// inspectNetwork returns the cached inspect data of the network and reads
+// it again when it is outdated. The caller must not hold the lock.
+func inspectNetwork(dc *dockerclient.DockerClient, id string) (*dockerclient.NetworkResource, error) {
+	ni, err := networkDetails.get(id, func() (interface{}, error) {
+		return dc.InspectNetwork(id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ni.(*dockerclient.NetworkResource), nil
+}
+
+// networkList shows the networks of the docker host with their member
+// containers. The selected container can be connected to or disconnected
+// from the network under the cursor.
+func networkList() (dockerDrawer, keyHandler, ui.GridBufferer) {
+	var (
+		networks []*dockerclient.NetworkResource
+		lineNets []int
+		refresh  refreshGuard
+		failed   bool
+	)
+	list := newSelectList(networksLabel("container"))
+
+	drawer := func(dc *dockerclient.DockerClient) {
+		if !refresh.start() {
+			return
+		}
+		defer refresh.done()
+
+		nets, err := dc.ListNetworks("")
+		if err != nil {
+			lock.Lock()
+			list.message(fmt.Sprintf("cannot read networks: %s", err))
+			failed = true
+			lock.Unlock()
+			return
+		}
+		sort.Sort(networksByName(nets))
+		for i, n := range nets {
+			if ni, err := inspectNetwork(dc, n.ID); err == nil {
+				nets[i] = ni
+			}
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		if failed {
+			list.msg = ""
+			failed = false
+		}
+		known := make(map[string]bool)
+		for _, n := range nets {
+			known[n.ID] = true
+		}
+		networkDetails.keep(known)
+		networks = nets
+		lineNets = nil
+		var lines []string
+		for i, n := range networks {
+			lines = append(lines, genNetworkListName(n))
+			lineNets = append(lineNets, i)
+			var ids []string
+			for id := range n.Containers {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			for _, id := range ids {
+				ep := n.Containers[id]
+				lines = append(lines, fmt.Sprintf("    %-30s %s %s", containerNameByID(id), ep.IPv4Address, ep.IPv6Address))
+				lineNets = append(lineNets, i)
+			}
+		}
+		list.label = networksLabel(selectedContainerName())
+		list.setLines(lines)
+	}
+
+	// changed drops the cached members of the network and shows it again.
+	changed := func(dc *dockerclient.DockerClient, n *dockerclient.NetworkResource) {
+		lock.Lock()
+		networkDetails.drop(n.ID)
+		lock.Unlock()
+		drawer(dc)
+	}
+
+	keys := func(dc *dockerclient.DockerClient, key string) bool {
+		if list.handleKey(key) {
+			return true
+		}
+		if key != "c" && key != "x" {
+			return false
+		}
+		lock.Lock()
+		i, ok := list.selected(len(lineNets))
+		if !ok || containerDetailsID == "" {
+			lock.Unlock()
+			return true
+		}
+		n := networks[lineNets[i]]
+		id := containerDetailsID
+		name := selectedContainerName()
+		lock.Unlock()
+		if key == "c" {
+			if err := dc.ConnectNetwork(n.ID, id); err != nil {
+				list.message(fmt.Sprintf("cannot connect %s to %s: %s", name, n.Name, err))
+			} else {
+				list.message(fmt.Sprintf("connected %s to %s", name, n.Name))
+			}
+			changed(dc, n)
+			return true
+		}
+		list.ask(fmt.Sprintf("Disconnect %s from %s?", name, n.Name), func() string {
+			if err := dc.DisconnectNetwork(n.ID, id); err != nil {
+				return fmt.Sprintf("cannot disconnect %s from %s: %s", name, n.Name, err)
+			}
+			changed(dc, n)
+			return fmt.Sprintf("disconnected %s from %s", name, n.Name)
+		})
+		return true
+	}
+	return drawer, keys, list
+}
+
+type networksByName []*dockerclient.NetworkResource
+
+func (s networksByName) Len() int           { return len(s) }
+func (s networksByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s networksByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+func genNetworkListName(n *dockerclient.NetworkResource) string {
+	var subnets []string
+	for _, c := range n.IPAM.Config {
+		subnets = append(subnets, c.Subnet)
+	}
+	return fmt.Sprintf("%-30s %-8s %-8s %s", n.Name, n.Driver, n.Scope, strings.Join(subnets, ","))
+}
+
+// containerNameByID returns the name of the running container with the
+// given id or the short id. The caller must hold the lock.
+func containerNameByID(id string) string {
+	for _, c := range allcontainers {
+		if c.Id == id {
+			return containerName(c)
+		}
+	}
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// selectedContainerName returns the name of the container which is shown
+// in the details. The caller must hold the lock.
+func selectedContainerName() string {
+	if containerDetailsID == "" {
+		return "container"
+	}
+	return containerNameByID(containerDetailsID)
+}
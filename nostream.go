@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samalba/dockerclient"
+)
+
+// the time to wait for the samples of all containers
+const noStreamTimeout = 10 * time.Second
+
+var noStream = flag.Bool("no-stream", false, "print the statistics of all containers once and exit")
+
+func collectStats(id string, stats *dockerclient.Stats, errs chan error, data ...interface{}) {
+	lock.Lock()
+	defer lock.Unlock()
+	dat := statsData[id]
+	if len(dat) > 0 && dat[len(dat)-1].Read == stats.Read {
+		// same stat twice, ignore
+		return
+	}
+	statsData[id] = append(dat, stats)
+}
+
+// sampled returns true if there are two stats of every container. The
+// caller must hold the lock.
+func sampled() bool {
+	for _, c := range allcontainers {
+		if len(statsData[c.Id]) < 2 {
+			return false
+		}
+	}
+	return true
+}
+
+// printStats collects two stats of every running container and prints a
+// table with their values to w. Containers without two stats are printed
+// with "--" and make printStats return an error after the table is written.
+func printStats(dc *dockerclient.DockerClient, w io.Writer) error {
+	containers, err := dc.ListContainers(false, false, "")
+	if err != nil {
+		return err
+	}
+	lock.Lock()
+	allcontainers = containers
+	lock.Unlock()
+	// every stream sends at most one error, so nobody blocks on it
+	errs := make(chan error, len(containers))
+	for i := range containers {
+		dc.StartMonitorStats(containers[i].Id, collectStats, errs, &containers[i])
+	}
+	for timeout := time.Now().Add(noStreamTimeout); time.Now().Before(timeout); time.Sleep(100 * time.Millisecond) {
+		lock.Lock()
+		done := sampled()
+		lock.Unlock()
+		if done {
+			break
+		}
+	}
+	dc.StopAllMonitorStats()
+	for done := false; !done; {
+		select {
+		case err := <-errs:
+			fmt.Fprintf(os.Stderr, "cannot read the statistics: %v\n", err)
+		default:
+			done = true
+		}
+	}
+
+	for _, c := range containers {
+		if n, err := countProcesses(dc, c.Id); err == nil {
+			lock.Lock()
+			containerPids.put(c.Id, n)
+			lock.Unlock()
+		}
+	}
+
+	lock.Lock()
+	rows := tableRows{rows: genTableRows()}
+	cpus := make(map[string]float64)
+	for _, c := range containers {
+		if stats := statsData[c.Id]; len(stats) >= 2 {
+			cpus[c.Id] = cpuPercentFloat(stats, len(stats)-1)
+		}
+	}
+	lock.Unlock()
+	rows.sort()
+
+	var missing []string
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tCPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O\tPIDS\tUPTIME")
+	for _, r := range rows.rows {
+		cpu, ok := cpus[r.ID]
+		if !ok {
+			missing = append(missing, r.Name)
+			fmt.Fprintf(tw, "%s\t--\t--\t--\t--\t--\t--\t%s\n", r.Name, r.Uptime)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%.2f%%\t%s / %s\t%d%%\t%s / %s\t%s / %s\t%d\t%s\n", r.Name, cpu,
+			memAsString(r.MemUsage), memAsString(r.MemLimit), r.MemPercent,
+			memAsString(r.RxBytes), memAsString(r.TxBytes),
+			memAsString(r.BlkioRead), memAsString(r.BlkioWrite), r.Pids, r.Uptime)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no statistics of %s within %s", strings.Join(missing, ", "), noStreamTimeout)
+	}
+	return nil
+}
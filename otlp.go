@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/samalba/dockerclient"
+)
+
+var (
+	otlpEndpoint = flag.String("otlp", "", "push metrics to this OTLP/HTTP endpoint, e.g. http://localhost:4318/v1/metrics")
+	otlpInterval = flag.Duration("otlp-interval", 10*time.Second, "the interval of the OTLP metrics export")
+	// the error of the last export, it is shown in the title
+	otlpErr error
+)
+
+// The following types are the JSON encoding of the OTLP metrics protocol,
+// see https://github.com/open-telemetry/opentelemetry-proto.
+type otlpExportRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpMetric struct {
+	Name  string     `json:"name"`
+	Unit  string     `json:"unit"`
+	Gauge *otlpGauge `json:"gauge,omitempty"`
+	Sum   *otlpSum   `json:"sum,omitempty"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpSum struct {
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+	AggregationTemporality int             `json:"aggregationTemporality"`
+	IsMonotonic            bool            `json:"isMonotonic"`
+}
+
+type otlpDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	AsDouble          *float64       `json:"asDouble,omitempty"`
+	AsInt             *string        `json:"asInt,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+const otlpCumulative = 2
+
+func otlpAttr(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: value}}
+}
+
+func otlpTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func otlpDouble(v float64, t time.Time, attrs ...otlpKeyValue) otlpDataPoint {
+	return otlpDataPoint{Attributes: attrs, TimeUnixNano: otlpTime(t), AsDouble: &v}
+}
+
+func otlpInt(v uint64, start, t time.Time, attrs ...otlpKeyValue) otlpDataPoint {
+	s := strconv.FormatUint(v, 10)
+	dp := otlpDataPoint{Attributes: attrs, TimeUnixNano: otlpTime(t), AsInt: &s}
+	if !start.IsZero() {
+		dp.StartTimeUnixNano = otlpTime(start)
+	}
+	return dp
+}
+
+func otlpGaugeMetric(name, unit string, dps ...otlpDataPoint) otlpMetric {
+	return otlpMetric{Name: name, Unit: unit, Gauge: &otlpGauge{DataPoints: dps}}
+}
+
+func otlpCounterMetric(name, unit string, dps ...otlpDataPoint) otlpMetric {
+	return otlpMetric{Name: name, Unit: unit, Sum: &otlpSum{DataPoints: dps, AggregationTemporality: otlpCumulative, IsMonotonic: true}}
+}
+
+// otlpContainerMetrics returns the metrics of the container. The counters
+// start when the container was started, they are reset by a restart.
+func otlpContainerMetrics(c dockerclient.Container, host string, start time.Time, stats []*dockerclient.Stats) (otlpResourceMetrics, bool) {
+	s, ok := lastSample(stats)
+	if !ok {
+		return otlpResourceMetrics{}, false
+	}
+	last := stats[len(stats)-1]
+	t := last.Read
+	return otlpResourceMetrics{
+		Resource: otlpResource{Attributes: []otlpKeyValue{
+			otlpAttr("container.id", c.Id),
+			otlpAttr("container.name", containerName(c)),
+			otlpAttr("container.image.name", c.Image),
+			otlpAttr("host.name", host),
+		}},
+		ScopeMetrics: []otlpScopeMetrics{{
+			Scope: otlpScope{Name: "dockmon", Version: version},
+			Metrics: []otlpMetric{
+				otlpGaugeMetric("container.cpu.utilization", "1", otlpDouble(float64(s.CPUPercent)/100, t)),
+				otlpGaugeMetric("container.memory.usage", "By", otlpInt(s.MemUsage, time.Time{}, t)),
+				otlpGaugeMetric("container.memory.limit", "By", otlpInt(s.MemLimit, time.Time{}, t)),
+				otlpCounterMetric("container.network.io", "By",
+					otlpInt(last.NetworkStats.RxBytes, start, t, otlpAttr("network.io.direction", "receive")),
+					otlpInt(last.NetworkStats.TxBytes, start, t, otlpAttr("network.io.direction", "transmit"))),
+				otlpCounterMetric("container.disk.io", "By",
+					otlpInt(blkioBytes(&last.BlkioStats, "Read"), start, t, otlpAttr("disk.io.direction", "read")),
+					otlpInt(blkioBytes(&last.BlkioStats, "Write"), start, t, otlpAttr("disk.io.direction", "write"))),
+			},
+		}},
+	}, true
+}
+
+func otlpHostName(dc *dockerclient.DockerClient) string {
+	if info, err := dc.Info(); err == nil && info.Name != "" {
+		return info.Name
+	}
+	h, _ := os.Hostname()
+	return h
+}
+
+func otlpExport(dc *dockerclient.DockerClient, client *http.Client, host string) error {
+	lock.Lock()
+	containers := append([]dockerclient.Container(nil), allcontainers...)
+	lock.Unlock()
+	starts := make(map[string]time.Time)
+	for _, c := range containers {
+		if t, err := containerStartTime(dc, c.Id); err == nil {
+			starts[c.Id] = t
+		}
+	}
+
+	var req otlpExportRequest
+	lock.Lock()
+	for _, c := range containers {
+		if rm, ok := otlpContainerMetrics(c, host, starts[c.Id], statsData[c.Id]); ok {
+			req.ResourceMetrics = append(req.ResourceMetrics, rm)
+		}
+	}
+	lock.Unlock()
+	if len(req.ResourceMetrics) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(*otlpEndpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
+
+// startOTLPExport pushes the newest metrics of all containers to the
+// OTLP endpoint in the configured interval.
+func startOTLPExport(dc *dockerclient.DockerClient) {
+	host := otlpHostName(dc)
+	client := &http.Client{Timeout: *otlpInterval}
+	go func() {
+		for range time.Tick(*otlpInterval) {
+			// the collector may be down, try again next time
+			err := otlpExport(dc, client, host)
+			lock.Lock()
+			otlpErr = err
+			lock.Unlock()
+		}
+	}()
+}
+
+// otlpError returns the error of the last export.
+func otlpError() error {
+	lock.Lock()
+	defer lock.Unlock()
+	return otlpErr
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+
+	ui "github.com/gizak/termui"
+)
+
+// the height of the title of the main panel
+const titleHeight = 3
+
+// compactView shows one line per container instead of the sparklines.
+var compactView bool
+
+// pager selects the page of the panel which contains the selected
+// container.
+type pager struct {
+	page  int
+	pages int
+}
+
+// window returns the range of the n items on the page of the selected
+// item.
+func (p *pager) window(n, perPage, selected int) (int, int) {
+	if perPage < 1 {
+		perPage = 1
+	}
+	p.pages = (n + perPage - 1) / perPage
+	p.page = 0
+	if selected > 0 {
+		p.page = selected / perPage
+	}
+	// a selection beyond the items shows the last page
+	if p.pages > 0 && p.page >= p.pages {
+		p.page = p.pages - 1
+	}
+	from := p.page * perPage
+	to := from + perPage
+	if to > n {
+		to = n
+	}
+	if from > to {
+		from = to
+	}
+	return from, to
+}
+
+// label returns the page for the border label, if there is more than one
+// page.
+func (p *pager) label() string {
+	if p.pages < 2 {
+		return ""
+	}
+	return fmt.Sprintf(" (page %d/%d)", p.page+1, p.pages)
+}
+
+// maxPanelHeight returns the height the widget may use so that all rows of
+// its grid fit on the screen. The widgets which are stacked in a column
+// share its height. If the widget is not shown, the main panel is used.
+func maxPanelHeight(w ui.GridBufferer) int {
+	h := ui.TermHeight() - titleHeight
+	grids := []*ui.Grid{ui.Body}
+	if len(uiStack) > 0 {
+		grids = append(grids, uiStack[0])
+	}
+	for _, g := range grids {
+		if g == nil || len(g.Rows) < 2 {
+			continue
+		}
+		if n, ok := stackedWidgets(g, w); ok {
+			h /= (len(g.Rows) - 1) * n
+			break
+		}
+	}
+	if h < 5 {
+		h = 5
+	}
+	return h
+}
+
+// stackedWidgets returns the number of widgets in the column of the grid
+// which contains the widget.
+func stackedWidgets(g *ui.Grid, w ui.GridBufferer) (int, bool) {
+	for _, r := range g.Rows {
+		for _, c := range r.Cols {
+			ws := rowWidgets(c)
+			for _, cw := range ws {
+				if cw == w {
+					return len(ws), true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+func rowWidgets(r *ui.Row) []ui.GridBufferer {
+	var res []ui.GridBufferer
+	if r.Widget != nil {
+		res = append(res, r.Widget)
+	}
+	for _, c := range r.Cols {
+		res = append(res, rowWidgets(c)...)
+	}
+	return res
+}
+
+// selectedGroup returns the index of the group which contains the selected
+// container.
+func selectedGroup(groups []*containerGroup) int {
+	for i, g := range groups {
+		for _, m := range g.Members {
+			if m == containerDetailsIndex {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// compact returns true if the panel shows one line per container.
+func (p *historyPanel) compact() bool {
+	return compactView && !p.lines
+}
+
+// window returns the range of the groups which fit into the panel.
+func (p *historyPanel) window(groups []*containerGroup) (int, int) {
+	perPage := (maxPanelHeight(p) - 2) / 3
+	bc, bars := p.spark.(*colorBarChart)
+	switch {
+	case p.lines:
+		perPage = maxPanelHeight(p) / chartHeight
+	case p.compact():
+		perPage = maxPanelHeight(p) - 2
+	case bars && bc.Width > 2:
+		// the width is set by the grid, the inner width only when the
+		// chart is drawn
+		perPage = (bc.Width - 2) / (bc.BarWidth + bc.BarGap)
+	case bars:
+		// not aligned yet, show all bars
+		perPage = len(groups)
+	}
+	return p.pager.window(len(groups), perPage, selectedGroup(groups))
+}
+
+// addLine adds a line with a mini sparkline of the data for the compact
+// view.
+func (p *historyPanel) addLine(title string, data []int, color ui.Attribute) {
+	p.list.Items = append(p.list.Items, fmt.Sprintf("[%s](%s) %s", miniSparkline(data, 20), markup(color), title))
+	p.list.Height = len(p.list.Items) + 2
+}
+
+// pagedList is a list with a line per group of the panel.
+type pagedList struct {
+	*ui.List
+	pager
+	groups []*containerGroup
+}
+
+// groupAt returns the group of the list item at the given screen position.
+func (l *pagedList) groupAt(y int) (*containerGroup, bool) {
+	i := y - l.InnerY()
+	if i < 0 || i >= len(l.groups) {
+		return nil, false
+	}
+	return l.groups[i], true
+}
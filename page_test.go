@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestPagerWindow(t *testing.T) {
+	tests := []struct {
+		n, perPage, selected int
+		from, to             int
+		page, pages          int
+		label                string
+	}{
+		{10, 4, 0, 0, 4, 0, 3, " (page 1/3)"},
+		{10, 4, 3, 0, 4, 0, 3, " (page 1/3)"},
+		{10, 4, 4, 4, 8, 1, 3, " (page 2/3)"},
+		{10, 4, 9, 8, 10, 2, 3, " (page 3/3)"},
+		{10, 10, 9, 0, 10, 0, 1, ""},
+		{10, 20, 5, 0, 10, 0, 1, ""},
+		// no selection shows the first page
+		{10, 4, -1, 0, 4, 0, 3, " (page 1/3)"},
+		// a selection beyond the items shows the last page
+		{10, 4, 12, 8, 10, 2, 3, " (page 3/3)"},
+		{0, 4, 0, 0, 0, 0, 0, ""},
+		// at least one item per page
+		{3, 0, 2, 2, 3, 2, 3, " (page 3/3)"},
+	}
+	for _, tt := range tests {
+		var p pager
+		from, to := p.window(tt.n, tt.perPage, tt.selected)
+		if from != tt.from || to != tt.to || p.page != tt.page || p.pages != tt.pages {
+			t.Errorf("window(%d, %d, %d) = %d, %d page %d/%d, want %d, %d page %d/%d", tt.n, tt.perPage, tt.selected,
+				from, to, p.page, p.pages, tt.from, tt.to, tt.page, tt.pages)
+		}
+		if got := p.label(); got != tt.label {
+			t.Errorf("window(%d, %d, %d): label %q, want %q", tt.n, tt.perPage, tt.selected, got, tt.label)
+		}
+	}
+}
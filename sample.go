@@ -0,0 +1,84 @@
+package main
+
+import (
+	"time"
+
+	"github.com/samalba/dockerclient"
+)
+
+// sample contains the metrics of a container which are derived from two
+// consecutive stats. All rates are per second.
+type sample struct {
+	Read       time.Time
+	CPUPercent int
+	MemUsage   uint64
+	MemLimit   uint64
+	RxBytes    uint64
+	TxBytes    uint64
+	BlkioRead  uint64
+	BlkioWrite uint64
+}
+
+func newSample(prev, cur *dockerclient.Stats) sample {
+	secs := cur.Read.Sub(prev.Read).Seconds()
+	return sample{
+		Read:       cur.Read,
+		CPUPercent: cpuPercent([]*dockerclient.Stats{prev, cur}, 1),
+		MemUsage:   cur.MemoryStats.Usage,
+		MemLimit:   cur.MemoryStats.Limit,
+		RxBytes:    perSecond(cur.NetworkStats.RxBytes, prev.NetworkStats.RxBytes, secs),
+		TxBytes:    perSecond(cur.NetworkStats.TxBytes, prev.NetworkStats.TxBytes, secs),
+		BlkioRead:  perSecond(blkioBytes(&cur.BlkioStats, "Read"), blkioBytes(&prev.BlkioStats, "Read"), secs),
+		BlkioWrite: perSecond(blkioBytes(&cur.BlkioStats, "Write"), blkioBytes(&prev.BlkioStats, "Write"), secs),
+	}
+}
+
+// lastSample returns the sample of the two newest stats. The second
+// return value is false if there are not enough stats.
+func lastSample(stats []*dockerclient.Stats) (sample, bool) {
+	if len(stats) < 2 {
+		return sample{}, false
+	}
+	return newSample(stats[len(stats)-2], stats[len(stats)-1]), true
+}
+
+func (s sample) memPercent() int {
+	if s.MemLimit == 0 {
+		return 0
+	}
+	return int(100 * s.MemUsage / s.MemLimit)
+}
+
+// add sums up two samples, e.g. to get the metrics of a group of
+// containers.
+func (s sample) add(o sample) sample {
+	if o.Read.After(s.Read) {
+		s.Read = o.Read
+	}
+	s.CPUPercent += o.CPUPercent
+	s.MemUsage += o.MemUsage
+	s.MemLimit += o.MemLimit
+	s.RxBytes += o.RxBytes
+	s.TxBytes += o.TxBytes
+	s.BlkioRead += o.BlkioRead
+	s.BlkioWrite += o.BlkioWrite
+	return s
+}
+
+func blkioBytes(stats *dockerclient.BlkioStats, op string) uint64 {
+	var res uint64
+	for _, e := range stats.IoServiceBytesRecursive {
+		if e.Op == op {
+			res += e.Value
+		}
+	}
+	return res
+}
+
+func perSecond(cur, prev uint64, secs float64) uint64 {
+	// counters are reset when a container restarts
+	if cur < prev || secs <= 0 {
+		return 0
+	}
+	return uint64(float64(cur-prev) / secs)
+}
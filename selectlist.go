@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+
+	ui "github.com/gizak/termui"
+)
+
+// selectList is a list with a cursor. Actions on the selected item can
+// ask for a confirmation, which is shown in the border label. The list
+// scrolls so that the cursor stays visible below the optional header line.
+type selectList struct {
+	*ui.List
+	label    string
+	header   string
+	lines    []string
+	cursor   int
+	offset   int
+	visible  int
+	question string
+	action   func() string
+	msg      string
+}
+
+func newSelectList(label string) *selectList {
+	l := &selectList{List: ui.NewList(), label: label}
+	l.ItemFgColor = currentTheme.Items
+	l.BorderLabel = label
+	return l
+}
+
+// setLines replaces the items of the list and keeps the cursor in range.
+func (l *selectList) setLines(lines []string) {
+	l.lines = lines
+	l.update()
+}
+
+func (l *selectList) update() {
+	if l.cursor >= len(l.lines) {
+		l.cursor = len(l.lines) - 1
+	}
+	if l.cursor < 0 {
+		l.cursor = 0
+	}
+	// the list reaches down to the bottom of the screen
+	visible := ui.TermHeight() - l.Y - 2
+	if l.header != "" {
+		visible--
+	}
+	if visible < 1 {
+		visible = 1
+	}
+	l.visible = visible
+	if l.cursor < l.offset {
+		l.offset = l.cursor
+	}
+	if l.cursor >= l.offset+visible {
+		l.offset = l.cursor - visible + 1
+	}
+	if l.offset > len(l.lines)-visible {
+		l.offset = len(l.lines) - visible
+	}
+	if l.offset < 0 {
+		l.offset = 0
+	}
+	end := l.offset + visible
+	if end > len(l.lines) {
+		end = len(l.lines)
+	}
+	var items []string
+	if l.header != "" {
+		items = append(items, l.header)
+	}
+	for i := l.offset; i < end; i++ {
+		s := l.lines[i]
+		if i == l.cursor {
+			s = highlight(stripMarkup(s))
+		}
+		items = append(items, s)
+	}
+	label := l.label
+	if l.offset > 0 || end < len(l.lines) {
+		label = fmt.Sprintf("%s %d-%d of %d", l.label, l.offset+1, end, len(l.lines))
+	}
+	switch {
+	case l.question != "":
+		l.BorderLabel = fmt.Sprintf("%s (y/n)", l.question)
+	case l.msg != "":
+		l.BorderLabel = fmt.Sprintf("%s - %s", label, l.msg)
+	default:
+		l.BorderLabel = label
+	}
+	l.Items = items
+	l.Height = len(items) + 2
+}
+
+// ask shows the question and runs the action when it is confirmed with
+// 'y'. The result of the action is shown as message.
+func (l *selectList) ask(question string, action func() string) {
+	l.question = question
+	l.action = action
+	l.update()
+}
+
+// selected returns the index of the line under the cursor. The second
+// return value is false if the cursor is not on one of the first n lines.
+func (l *selectList) selected(n int) (int, bool) {
+	return l.cursor, l.cursor >= 0 && l.cursor < n && l.cursor < len(l.lines)
+}
+
+// message shows the given message in the border label.
+func (l *selectList) message(msg string) {
+	l.msg = msg
+	l.update()
+}
+
+// handleKey moves the cursor and answers questions. It returns false if
+// the key was not consumed.
+func (l *selectList) handleKey(key string) bool {
+	if l.question != "" {
+		l.question = ""
+		if key == "y" {
+			l.msg = l.action()
+		}
+		l.update()
+		return true
+	}
+	switch key {
+	case "<up>":
+		l.cursor--
+	case "<down>":
+		l.cursor++
+	case "<previous>":
+		l.cursor -= l.visible
+	case "<next>":
+		l.cursor += l.visible
+	default:
+		return false
+	}
+	l.update()
+	return true
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/samalba/dockerclient"
+)
+
+var (
+	snapshotDir    = flag.String("snapshot-dir", ".", "the directory for the snapshot reports written with 's'")
+	snapshotFormat = flag.String("snapshot-format", "md", "the format of the snapshot reports: md or json")
+)
+
+type snapshotContainer struct {
+	Container dockerclient.Container
+	Latest    *sample `json:",omitempty"`
+	History   []sample
+}
+
+type snapshotReport struct {
+	Time       time.Time
+	Containers []snapshotContainer
+	Selected   *dockerclient.ContainerInfo `json:",omitempty"`
+}
+
+func newSnapshotReport(dc *dockerclient.DockerClient) *snapshotReport {
+	rep := &snapshotReport{Time: time.Now()}
+	lock.Lock()
+	for _, c := range allcontainers {
+		sc := snapshotContainer{Container: c}
+		dat := statsData[c.Id]
+		for i := 1; i < len(dat); i++ {
+			sc.History = append(sc.History, newSample(dat[i-1], dat[i]))
+		}
+		if len(sc.History) > 0 {
+			sc.Latest = &sc.History[len(sc.History)-1]
+		}
+		rep.Containers = append(rep.Containers, sc)
+	}
+	selected := containerDetailsID
+	lock.Unlock()
+
+	if selected != "" {
+		if ci, err := dc.InspectContainer(selected); err == nil {
+			// reports are attached to tickets, never write secrets
+			if *hideEnv {
+				ci.Config.Env = nil
+			} else {
+				ci.Config.Env = maskEnv(ci.Config.Env)
+			}
+			rep.Selected = ci
+		}
+	}
+	return rep
+}
+
+func (rep *snapshotReport) markdown() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# dockmon snapshot %s\n\n", rep.Time.Format(time.RFC3339))
+
+	buf.WriteString("## Containers\n\n")
+	buf.WriteString("| # | Name | Id | Image | Status | CPU % | Memory | Limit | Rx/s | Tx/s | Blkio read/s | Blkio write/s |\n")
+	buf.WriteString("|---|------|----|-------|--------|-------|--------|-------|------|------|--------------|---------------|\n")
+	for i, sc := range rep.Containers {
+		c := sc.Container
+		var s sample
+		if sc.Latest != nil {
+			s = *sc.Latest
+		}
+		fmt.Fprintf(&buf, "| %d | %s | %s | %s | %s | %d | %s | %s | %s | %s | %s | %s |\n",
+			i, containerName(c), c.Id[:12], c.Image, c.Status, s.CPUPercent,
+			memAsString(s.MemUsage), memAsString(s.MemLimit), memAsString(s.RxBytes), memAsString(s.TxBytes),
+			memAsString(s.BlkioRead), memAsString(s.BlkioWrite))
+	}
+
+	buf.WriteString("\n## History\n")
+	for _, sc := range rep.Containers {
+		fmt.Fprintf(&buf, "\n### %s\n\n", containerName(sc.Container))
+		buf.WriteString("| Time | CPU % | Memory | Rx/s | Tx/s | Blkio read/s | Blkio write/s |\n")
+		buf.WriteString("|------|-------|--------|------|------|--------------|---------------|\n")
+		for _, s := range sc.History {
+			fmt.Fprintf(&buf, "| %s | %d | %s | %s | %s | %s | %s |\n",
+				s.Read.Format("15:04:05"), s.CPUPercent, memAsString(s.MemUsage), memAsString(s.RxBytes),
+				memAsString(s.TxBytes), memAsString(s.BlkioRead), memAsString(s.BlkioWrite))
+		}
+	}
+
+	if rep.Selected != nil {
+		inspect, err := json.MarshalIndent(rep.Selected, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "\n## Inspect %s\n\n```json\n%s\n```\n", rep.Selected.Name, inspect)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeSnapshot writes a report of the current state to the snapshot
+// directory and returns the name of the written file.
+func writeSnapshot(dc *dockerclient.DockerClient) (string, error) {
+	rep := newSnapshotReport(dc)
+
+	var (
+		data []byte
+		err  error
+	)
+	switch *snapshotFormat {
+	case "md":
+		data, err = rep.markdown()
+	case "json":
+		data, err = json.MarshalIndent(rep, "", "  ")
+	default:
+		err = fmt.Errorf("unknown snapshot format: %s", *snapshotFormat)
+	}
+	if err != nil {
+		return "", err
+	}
+	fn := filepath.Join(*snapshotDir, fmt.Sprintf("dockmon-%s.%s", rep.Time.Format("20060102-150405"), *snapshotFormat))
+	return fn, ioutil.WriteFile(fn, data, 0644)
+}
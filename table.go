@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samalba/dockerclient"
+)
+
+const tableFormat = "%-25s %7s %-21s %7s %-17s %-17s %6s %-16s %s"
+
+var (
+	tableColumns = []string{"NAME", "CPU %", "MEM USAGE / LIMIT", "MEM %", "NET I/O", "BLOCK I/O", "PIDS", "UPTIME"}
+	// the number of processes of the containers
+	containerPids = newTTLCache(pidsTTL)
+	pidsRefresh   refreshGuard
+)
+
+// tableRow contains the values of a container in the table view.
+type tableRow struct {
+	Index      int
+	ID         string
+	Name       string
+	CPUPercent int
+	MemUsage   uint64
+	MemLimit   uint64
+	MemPercent int
+	RxBytes    uint64
+	TxBytes    uint64
+	BlkioRead  uint64
+	BlkioWrite uint64
+	Pids       int
+	Uptime     string
+	Health     string
+	Started    time.Time
+	CPUHistory []int
+}
+
+type tableRows struct {
+	rows []tableRow
+	col  int
+	desc bool
+}
+
+// compareUint returns -1, 0 or 1 if a is less, equal or greater than b.
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compare compares the rows by the sort column.
+func (t tableRows) compare(a, b tableRow) int {
+	switch t.col {
+	case 1:
+		return compareUint(uint64(a.CPUPercent), uint64(b.CPUPercent))
+	case 2:
+		return compareUint(a.MemUsage, b.MemUsage)
+	case 3:
+		return compareUint(uint64(a.MemPercent), uint64(b.MemPercent))
+	case 4:
+		return compareUint(a.RxBytes+a.TxBytes, b.RxBytes+b.TxBytes)
+	case 5:
+		return compareUint(a.BlkioRead+a.BlkioWrite, b.BlkioRead+b.BlkioWrite)
+	case 6:
+		return compareUint(uint64(a.Pids), uint64(b.Pids))
+	case 7:
+		// the earlier the container was started, the longer its uptime
+		switch {
+		case a.Started.After(b.Started):
+			return -1
+		case a.Started.Before(b.Started):
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a.Name, b.Name)
+}
+
+// sort sorts the rows by the sort column. Rows with equal values are
+// sorted by their name, so they keep their place between two updates.
+func (t tableRows) sort() {
+	sort.SliceStable(t.rows, func(i, j int) bool {
+		a, b := t.rows[i], t.rows[j]
+		if c := t.compare(a, b); c != 0 {
+			return (c < 0) != t.desc
+		}
+		return a.Name < b.Name
+	})
+}
+
+// containerUptime returns the uptime from the status of the container,
+// e.g. "5 minutes" for "Up 5 minutes (healthy)".
+func containerUptime(c dockerclient.Container) string {
+	s := strings.TrimPrefix(c.Status, "Up ")
+	if i := strings.Index(s, " ("); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// genTableRows creates the rows of all containers. The caller must hold
+// the lock.
+func genTableRows() []tableRow {
+	var rows []tableRow
+	for i, c := range allcontainers {
+		stats := statsData[c.Id]
+		s, _ := lastSample(stats)
+		pids, _ := containerPids.lookup(c.Id)
+		started, _ := containerStarts.lookup(c.Id)
+		r := tableRow{
+			Index:      i,
+			ID:         c.Id,
+			Name:       containerName(c),
+			CPUPercent: s.CPUPercent,
+			MemUsage:   s.MemUsage,
+			MemLimit:   s.MemLimit,
+			MemPercent: s.memPercent(),
+			Pids:       cachedInt(pids),
+			Uptime:     containerUptime(c),
+			Health:     containerHealth(c),
+			Started:    cachedTime(started),
+			CPUHistory: genCPUSystemUsage(stats),
+		}
+		if len(stats) > 0 {
+			last := stats[len(stats)-1]
+			r.RxBytes = last.NetworkStats.RxBytes
+			r.TxBytes = last.NetworkStats.TxBytes
+			r.BlkioRead = blkioBytes(&last.BlkioStats, "Read")
+			r.BlkioWrite = blkioBytes(&last.BlkioStats, "Write")
+		}
+		rows = append(rows, r)
+	}
+	return rows
+}
+
+func genTableHeader(col int, desc bool) string {
+	var cols []interface{}
+	for i, c := range tableColumns {
+		if i == col {
+			if desc {
+				c += " ▼"
+			} else {
+				c += " ▲"
+			}
+		}
+		cols = append(cols, c)
+	}
+	return fmt.Sprintf(tableFormat, append(cols, "CPU HISTORY")...)
+}
+
+func genTableLine(r tableRow) string {
+	name := r.Name
+	if len(name) > 25 {
+		name = name[:25]
+	}
+	if clr, ok := healthColors()[r.Health]; ok {
+		name = fmt.Sprintf("[%-25s](%s)", name, markup(clr))
+	}
+	pids := "-"
+	if r.Pids > 0 {
+		pids = fmt.Sprintf("%d", r.Pids)
+	}
+	return fmt.Sprintf(tableFormat, name,
+		fmt.Sprintf("%d%%", r.CPUPercent),
+		fmt.Sprintf("%s / %s", memAsString(r.MemUsage), memAsString(r.MemLimit)),
+		fmt.Sprintf("%d%%", r.MemPercent),
+		fmt.Sprintf("%s / %s", memAsString(r.RxBytes), memAsString(r.TxBytes)),
+		fmt.Sprintf("%s / %s", memAsString(r.BlkioRead), memAsString(r.BlkioWrite)),
+		pids, r.Uptime, miniSparkline(r.CPUHistory, 20))
+}
+
+// updatePids counts the processes of the containers whose count is
+// outdated and inspects the start time of new containers. The containers
+// are queried in the background.
+func updatePids(dc *dockerclient.DockerClient) {
+	if !pidsRefresh.start() {
+		return
+	}
+	var ids []string
+	running := make(map[string]bool)
+	lock.Lock()
+	for _, c := range allcontainers {
+		running[c.Id] = true
+		ids = append(ids, c.Id)
+	}
+	containerPids.keep(running)
+	lock.Unlock()
+	go func() {
+		defer pidsRefresh.done()
+		for _, id := range ids {
+			containerStartTime(dc, id)
+			containerPids.get(id, func() (interface{}, error) {
+				return countProcesses(dc, id)
+			})
+		}
+	}()
+}
+
+// cachedInt returns the int value of a cache or 0.
+func cachedInt(v interface{}) int {
+	n, _ := v.(int)
+	return n
+}
+
+// cachedTime returns the time value of a cache or the zero time.
+func cachedTime(v interface{}) time.Time {
+	t, _ := v.(time.Time)
+	return t
+}
+
+// countProcesses returns the number of processes in the container. The
+// vendored client has no call for the processes of a container.
+func countProcesses(dc *dockerclient.DockerClient, id string) (int, error) {
+	resp, err := dc.HTTPClient.Get(fmt.Sprintf("%s/containers/%s/top", dc.URL.String(), id))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("cannot list the processes of container %s: %s", id, resp.Status)
+	}
+	var top struct {
+		Processes [][]string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&top); err != nil {
+		return 0, err
+	}
+	return len(top.Processes), nil
+}
+
+// containerTable shows all containers in a table which can be sorted by
+// every column. show is called with the index of the container under the
+// cursor when it is selected. The cursor stays on the selected container
+// when the rows are sorted again.
+func containerTable(show func(int)) (dockerDrawer, keyHandler, *selectList) {
+	var rows tableRows
+	var selectedID string
+	list := newSelectList("")
+	// update sorts the rows and shows them. The caller must hold the lock.
+	update := func() {
+		rows.sort()
+		list.label = fmt.Sprintf("Containers (%s/%s to sort, %s to reverse, %s for details)",
+			quoteKey(boundKey("table", "sort-prev")), quoteKey(boundKey("table", "sort-next")), quoteKey(boundKey("table", "reverse")), quoteKey(boundKey("list", "select")))
+		list.header = genTableHeader(rows.col, rows.desc)
+		var lines []string
+		for i, r := range rows.rows {
+			if r.ID == selectedID {
+				list.cursor = i
+			}
+			lines = append(lines, genTableLine(r))
+		}
+		list.setLines(lines)
+		if i, ok := list.selected(len(rows.rows)); ok {
+			selectedID = rows.rows[i].ID
+		}
+	}
+	drawer := func(dc *dockerclient.DockerClient) {
+		updatePids(dc)
+		lock.Lock()
+		defer lock.Unlock()
+		rows.rows = genTableRows()
+		update()
+	}
+
+	keys := func(dc *dockerclient.DockerClient, key string) bool {
+		if key == "<enter>" {
+			// show takes the lock itself
+			lock.Lock()
+			i, ok := list.selected(len(rows.rows))
+			idx := -1
+			if ok {
+				idx = rows.rows[i].Index
+			}
+			lock.Unlock()
+			if idx >= 0 {
+				show(idx)
+			}
+			return true
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		if list.handleKey(key) {
+			if i, ok := list.selected(len(rows.rows)); ok {
+				selectedID = rows.rows[i].ID
+			}
+			return true
+		}
+		switch key {
+		case "<left>":
+			rows.col = (rows.col + len(tableColumns) - 1) % len(tableColumns)
+		case "<right>":
+			rows.col = (rows.col + 1) % len(tableColumns)
+		case "o":
+			rows.desc = !rows.desc
+		default:
+			return false
+		}
+		update()
+		return true
+	}
+	return drawer, keys, list
+}
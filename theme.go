@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	ui "github.com/gizak/termui"
+)
+
+// theme contains the colors of the widgets and of the metrics.
+type theme struct {
+	Fg         ui.Attribute
+	Border     ui.Attribute
+	Label      ui.Attribute
+	Items      ui.Attribute
+	Highlight  string // the markup of the selected list item
+	Axes       ui.Attribute
+	CPU        ui.Attribute
+	Memory     ui.Attribute
+	Rx         ui.Attribute
+	Tx         ui.Attribute
+	BlkioRead  ui.Attribute
+	BlkioWrite ui.Attribute
+	Good       ui.Attribute
+	Warning    ui.Attribute
+	Critical   ui.Attribute
+	Info       ui.Attribute
+	Neutral    ui.Attribute
+	// the colors of the series of compared containers
+	Series []ui.Attribute
+}
+
+var themes = map[string]theme{
+	"dark": {
+		Fg:         ui.ColorWhite,
+		Border:     ui.ColorWhite,
+		Label:      ui.ColorGreen,
+		Items:      ui.ColorYellow,
+		Highlight:  "fg-black,bg-cyan",
+		Axes:       ui.ColorWhite,
+		CPU:        ui.ColorYellow,
+		Memory:     ui.ColorRed,
+		Rx:         ui.ColorGreen,
+		Tx:         ui.ColorBlue,
+		BlkioRead:  ui.ColorCyan,
+		BlkioWrite: ui.ColorMagenta,
+		Good:       ui.ColorGreen,
+		Warning:    ui.ColorYellow,
+		Critical:   ui.ColorRed,
+		Info:       ui.ColorCyan,
+		Neutral:    ui.ColorMagenta,
+		Series:     []ui.Attribute{ui.ColorYellow, ui.ColorCyan, ui.ColorMagenta, ui.ColorGreen},
+	},
+	"light": {
+		Fg:         ui.ColorBlack,
+		Border:     ui.ColorBlack,
+		Label:      ui.ColorBlue,
+		Items:      ui.ColorBlack,
+		Highlight:  "fg-white,bg-blue",
+		Axes:       ui.ColorBlack,
+		CPU:        ui.ColorBlue,
+		Memory:     ui.ColorRed,
+		Rx:         ui.ColorGreen,
+		Tx:         ui.ColorMagenta,
+		BlkioRead:  ui.ColorCyan,
+		BlkioWrite: ui.ColorMagenta,
+		Good:       ui.ColorGreen,
+		Warning:    ui.ColorMagenta,
+		Critical:   ui.ColorRed,
+		Info:       ui.ColorBlue,
+		Neutral:    ui.ColorCyan,
+		Series:     []ui.Attribute{ui.ColorBlue, ui.ColorRed, ui.ColorGreen, ui.ColorMagenta},
+	},
+	"high-contrast": {
+		Fg:         ui.ColorWhite | ui.AttrBold,
+		Border:     ui.ColorWhite | ui.AttrBold,
+		Label:      ui.ColorWhite | ui.AttrBold,
+		Items:      ui.ColorWhite | ui.AttrBold,
+		Highlight:  "fg-black,bg-white",
+		Axes:       ui.ColorWhite | ui.AttrBold,
+		CPU:        ui.ColorYellow | ui.AttrBold,
+		Memory:     ui.ColorRed | ui.AttrBold,
+		Rx:         ui.ColorGreen | ui.AttrBold,
+		Tx:         ui.ColorCyan | ui.AttrBold,
+		BlkioRead:  ui.ColorWhite | ui.AttrBold,
+		BlkioWrite: ui.ColorMagenta | ui.AttrBold,
+		Good:       ui.ColorGreen | ui.AttrBold,
+		Warning:    ui.ColorYellow | ui.AttrBold,
+		Critical:   ui.ColorRed | ui.AttrBold,
+		Info:       ui.ColorCyan | ui.AttrBold,
+		Neutral:    ui.ColorWhite,
+		Series:     []ui.Attribute{ui.ColorYellow | ui.AttrBold, ui.ColorCyan | ui.AttrBold, ui.ColorMagenta | ui.AttrBold, ui.ColorGreen | ui.AttrBold},
+	},
+	// no red/green pairs, which are hard to tell apart for most color
+	// blind people
+	"colorblind": {
+		Fg:         ui.ColorWhite,
+		Border:     ui.ColorWhite,
+		Label:      ui.ColorCyan,
+		Items:      ui.ColorWhite,
+		Highlight:  "fg-black,bg-cyan",
+		Axes:       ui.ColorWhite,
+		CPU:        ui.ColorCyan,
+		Memory:     ui.ColorMagenta,
+		Rx:         ui.ColorBlue,
+		Tx:         ui.ColorYellow,
+		BlkioRead:  ui.ColorBlue,
+		BlkioWrite: ui.ColorYellow,
+		Good:       ui.ColorBlue,
+		Warning:    ui.ColorYellow,
+		Critical:   ui.ColorMagenta,
+		Info:       ui.ColorCyan,
+		Neutral:    ui.ColorWhite,
+		Series:     []ui.Attribute{ui.ColorBlue, ui.ColorYellow, ui.ColorMagenta, ui.ColorWhite},
+	},
+	// used if NO_COLOR is set
+	"none": {
+		Highlight: "fg-reverse",
+		Warning:   ui.AttrUnderline,
+		Critical:  ui.AttrBold,
+		Series:    []ui.Attribute{ui.ColorDefault},
+	},
+}
+
+var currentTheme = themes["dark"]
+
+// The markup of termui knows no yellow. It is written as a combination of
+// attributes which is not used otherwise and turned into yellow by the
+// text builder of the theme.
+const (
+	markupYellow = "black,fg-underline,fg-reverse"
+	yellowMarker = ui.ColorBlack | ui.AttrUnderline | ui.AttrReverse
+)
+
+var markupColors = map[ui.Attribute]string{
+	ui.ColorDefault: "default",
+	ui.ColorBlack:   "black",
+	ui.ColorRed:     "red",
+	ui.ColorGreen:   "green",
+	ui.ColorYellow:  markupYellow,
+	ui.ColorBlue:    "blue",
+	ui.ColorMagenta: "magenta",
+	ui.ColorCyan:    "cyan",
+	ui.ColorWhite:   "white",
+}
+
+// setTheme selects the theme with the given name, the dark theme is the
+// default. The theme must be set before the widgets are created. If the
+// NO_COLOR environment variable is set, no colors are used at all.
+func setTheme(name string) error {
+	if os.Getenv("NO_COLOR") != "" {
+		name = "none"
+	}
+	if name == "" {
+		name = "dark"
+	}
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme: %s", name)
+	}
+	currentTheme = t
+	ui.DefaultTxBuilder = themeTxBuilder{ui.NewMarkdownTxBuilder()}
+	ui.ColorMap["fg"] = t.Fg
+	ui.ColorMap["border.fg"] = t.Border
+	ui.ColorMap["label.fg"] = t.Label
+	return nil
+}
+
+// markupPattern matches the colored parts of a list item, which may
+// contain one level of brackets like the markdown builder of termui.
+var markupPattern = regexp.MustCompile(`\[((?:[^\[\]]|\[[^\[\]]*\])+)\]\([a-z\s,-]+\)`)
+
+// stripMarkup removes the colors from a list item, termui cannot nest
+// them.
+func stripMarkup(s string) string {
+	return markupPattern.ReplaceAllString(s, "$1")
+}
+
+// markup returns the markup of a list item with the given color.
+func markup(clr ui.Attribute) string {
+	s := "fg-" + markupColors[clr&0xff]
+	if clr&ui.AttrBold != 0 {
+		s += ",fg-bold"
+	}
+	if clr&ui.AttrUnderline != 0 {
+		s += ",fg-underline"
+	}
+	return s
+}
+
+// themeTxBuilder builds the text of the widgets with the markup of termui
+// and colors the cells which are marked as yellow.
+type themeTxBuilder struct {
+	ui.TextBuilder
+}
+
+func (b themeTxBuilder) Build(s string, fg, bg ui.Attribute) []ui.Cell {
+	cs := b.TextBuilder.Build(s, fg, bg)
+	for i, c := range cs {
+		if c.Fg&^ui.AttrBold == yellowMarker {
+			cs[i].Fg = ui.ColorYellow | c.Fg&ui.AttrBold
+		}
+	}
+	return cs
+}
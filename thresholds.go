@@ -0,0 +1,96 @@
+package main
+
+import (
+	ui "github.com/gizak/termui"
+)
+
+// threshold contains the values above which a metric is shown in the
+// warning or critical color. A zero value disables the threshold.
+type threshold struct {
+	Warning  float64 `json:"warning"`
+	Critical float64 `json:"critical"`
+}
+
+// thresholdsConfig contains the thresholds of the metrics: the CPU usage
+// in percent, the memory usage in percent of the limit and the network
+// traffic in bytes per second.
+type thresholdsConfig struct {
+	CPU     threshold `json:"cpu"`
+	Memory  threshold `json:"memory"`
+	Network threshold `json:"network"`
+}
+
+var (
+	defaultThresholds = thresholdsConfig{
+		CPU:    threshold{Warning: 70, Critical: 90},
+		Memory: threshold{Warning: 70, Critical: 90},
+	}
+	thresholds = defaultThresholds
+)
+
+// color returns the color of the value: the critical or warning color of
+// the theme if the value exceeds a threshold, otherwise the given color.
+func (t threshold) color(v float64, normal ui.Attribute) ui.Attribute {
+	switch {
+	case t.Critical > 0 && v >= t.Critical:
+		return currentTheme.Critical
+	case t.Warning > 0 && v >= t.Warning:
+		return currentTheme.Warning
+	}
+	return normal
+}
+
+// colorBarChart is a bar chart with a color for every bar.
+type colorBarChart struct {
+	*ui.BarChart
+	BarColors []ui.Attribute
+	// the colors of the labels, ui.ColorDefault keeps the label color
+	LabelColors []ui.Attribute
+}
+
+func newColorBarChart() *colorBarChart {
+	return &colorBarChart{BarChart: ui.NewBarChart()}
+}
+
+// barBg returns the background of the cells of a bar with the given color.
+func barBg(clr ui.Attribute) ui.Attribute {
+	// spaces with the default color are transparent
+	if clr == ui.ColorDefault {
+		return clr | ui.AttrReverse
+	}
+	return clr
+}
+
+// Buffer implements the Bufferer interface.
+func (bc *colorBarChart) Buffer() ui.Buffer {
+	buf := bc.BarChart.Buffer()
+	bg := barBg(bc.BarColor)
+	for i, clr := range bc.BarColors {
+		x0 := bc.InnerX() + i*(bc.BarWidth+bc.BarGap)
+		if clr == bc.BarColor || x0+bc.BarWidth > bc.InnerX()+bc.InnerWidth() {
+			continue
+		}
+		for x := x0; x < x0+bc.BarWidth; x++ {
+			// the last row contains the labels
+			for y := bc.InnerY(); y < bc.InnerY()+bc.InnerHeight()-1; y++ {
+				if c := buf.At(x, y); c.Bg == bg {
+					c.Bg = barBg(clr)
+					buf.Set(x, y, c)
+				}
+			}
+		}
+	}
+	y := bc.InnerY() + bc.InnerHeight() - 1
+	for i, clr := range bc.LabelColors {
+		x0 := bc.InnerX() + i*(bc.BarWidth+bc.BarGap)
+		if clr == ui.ColorDefault || x0+bc.BarWidth > bc.InnerX()+bc.InnerWidth() {
+			continue
+		}
+		for x := x0; x < x0+bc.BarWidth; x++ {
+			c := buf.At(x, y)
+			c.Fg = clr
+			buf.Set(x, y, c)
+		}
+	}
+	return buf
+}
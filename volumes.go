@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	ui "github.com/gizak/termui"
+	"github.com/samalba/dockerclient"
+)
+
+// containerMounts caches the inspect data of all containers, running or
+// not, for the volumes. The data of a container is dropped when it is
+// created or destroyed.
+var containerMounts = newTTLCache(mountsTTL)
+
+// inspectMounts returns the cached inspect data of the container and
+// reads it again when it is outdated. The caller must not hold the lock.
+func inspectMounts(dc *dockerclient.DockerClient, id string) (*containerInspect, error) {
+	ci, err := containerMounts.get(id, func() (interface{}, error) {
+		return inspectContainerExt(dc, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ci.(*containerInspect), nil
+}
+
+// volumeList shows the volumes of the docker host and the containers which
+// mount them. Volumes which are not used by any container, running or
+// not, are orphans and can be removed.
+func volumeList() (dockerDrawer, keyHandler, ui.GridBufferer) {
+	var (
+		volumes []*dockerclient.Volume
+		users   map[string][]string
+		refresh refreshGuard
+		failed  bool
+	)
+	list := newSelectList(fmt.Sprintf("Volumes (%s/%s to remove an orphan)", quoteKey(boundKey("list", "select")), quoteKey(boundKey("volumes", "remove"))))
+
+	drawer := func(dc *dockerclient.DockerClient) {
+		if !refresh.start() {
+			return
+		}
+		defer refresh.done()
+
+		fail := func(err error) {
+			lock.Lock()
+			list.message(fmt.Sprintf("cannot read volumes: %s", err))
+			failed = true
+			lock.Unlock()
+		}
+		vols, err := dc.ListVolumes()
+		if err != nil {
+			fail(err)
+			return
+		}
+		containers, err := dc.ListContainers(true, false, "")
+		if err != nil {
+			fail(err)
+			return
+		}
+		sort.Sort(volumesByName(vols))
+		u := make(map[string][]string)
+		for _, c := range containers {
+			ci, err := inspectMounts(dc, c.Id)
+			if err != nil {
+				continue
+			}
+			for _, v := range vols {
+				if ci.usesVolume(v) {
+					u[v.Name] = append(u[v.Name], containerName(c))
+				}
+			}
+		}
+		var lines []string
+		for _, v := range vols {
+			lines = append(lines, genVolumeListName(v, u[v.Name]))
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		if failed {
+			list.msg = ""
+			failed = false
+		}
+		known := make(map[string]bool)
+		for _, c := range containers {
+			known[c.Id] = true
+		}
+		containerMounts.keep(known)
+		volumes = vols
+		users = u
+		list.setLines(lines)
+	}
+
+	keys := func(dc *dockerclient.DockerClient, key string) bool {
+		if list.handleKey(key) {
+			return true
+		}
+		if key != "d" && key != "<enter>" {
+			return false
+		}
+		lock.Lock()
+		i, ok := list.selected(len(volumes))
+		if !ok {
+			lock.Unlock()
+			return true
+		}
+		v := volumes[i]
+		u := users[v.Name]
+		lock.Unlock()
+		if len(u) > 0 {
+			list.message(fmt.Sprintf("%s is used by %s", v.Name, strings.Join(u, ", ")))
+			return true
+		}
+		list.ask(fmt.Sprintf("Remove volume %s?", v.Name), func() string {
+			if err := dc.RemoveVolume(v.Name); err != nil {
+				return fmt.Sprintf("cannot remove %s: %s", v.Name, err)
+			}
+			drawer(dc)
+			return fmt.Sprintf("removed %s", v.Name)
+		})
+		return true
+	}
+	return drawer, keys, list
+}
+
+type volumesByName []*dockerclient.Volume
+
+func (s volumesByName) Len() int           { return len(s) }
+func (s volumesByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s volumesByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+func genVolumeListName(v *dockerclient.Volume, users []string) string {
+	used := "ORPHAN"
+	if len(users) > 0 {
+		used = strings.Join(users, ",")
+	}
+	name := v.Name
+	if len(name) > 30 {
+		name = name[:27] + "..."
+	}
+	return fmt.Sprintf("%-30s %-8s %s %s", name, v.Driver, v.Mountpoint, used)
+}